@@ -0,0 +1,5 @@
+// Package shortliv1 holds the generated protobuf and gRPC code of the
+// shortli.v1 API.
+package shortliv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative shortli/v1/links.proto
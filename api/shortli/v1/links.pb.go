@@ -0,0 +1,1134 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: shortli/v1/links.proto
+
+package shortliv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShortCode     string                 `protobuf:"bytes,2,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	ShortUrl      string                 `protobuf:"bytes,3,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,4,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	ClickCount    int64                  `protobuf:"varint,6,opt,name=click_count,json=clickCount,proto3" json:"click_count,omitempty"`
+	BotClickCount int64                  `protobuf:"varint,7,opt,name=bot_click_count,json=botClickCount,proto3" json:"bot_click_count,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Preview       *Preview               `protobuf:"bytes,9,opt,name=preview,proto3" json:"preview,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_shortli_v1_links_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Link) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Link) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *Link) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *Link) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *Link) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Link) GetClickCount() int64 {
+	if x != nil {
+		return x.ClickCount
+	}
+	return 0
+}
+
+func (x *Link) GetBotClickCount() int64 {
+	if x != nil {
+		return x.BotClickCount
+	}
+	return 0
+}
+
+func (x *Link) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Link) GetPreview() *Preview {
+	if x != nil {
+		return x.Preview
+	}
+	return nil
+}
+
+// Preview overrides the social preview shown by link unfurlers. On update an
+// unset field is left unchanged and an empty string clears it.
+type Preview struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         *string                `protobuf:"bytes,1,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description   *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	ImageUrl      *string                `protobuf:"bytes,3,opt,name=image_url,json=imageUrl,proto3,oneof" json:"image_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Preview) Reset() {
+	*x = Preview{}
+	mi := &file_shortli_v1_links_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Preview) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Preview) ProtoMessage() {}
+
+func (x *Preview) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Preview.ProtoReflect.Descriptor instead.
+func (*Preview) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Preview) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *Preview) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *Preview) GetImageUrl() string {
+	if x != nil && x.ImageUrl != nil {
+		return *x.ImageUrl
+	}
+	return ""
+}
+
+type ShortenRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Tags        []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Returns the caller's existing link for the same URL instead of creating
+	// a new one. Defaults to true.
+	ReuseExisting *bool    `protobuf:"varint,3,opt,name=reuse_existing,json=reuseExisting,proto3,oneof" json:"reuse_existing,omitempty"`
+	Preview       *Preview `protobuf:"bytes,4,opt,name=preview,proto3" json:"preview,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenRequest) Reset() {
+	*x = ShortenRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenRequest) ProtoMessage() {}
+
+func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
+func (*ShortenRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ShortenRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetReuseExisting() bool {
+	if x != nil && x.ReuseExisting != nil {
+		return *x.ReuseExisting
+	}
+	return false
+}
+
+func (x *ShortenRequest) GetPreview() *Preview {
+	if x != nil {
+		return x.Preview
+	}
+	return nil
+}
+
+type ShortenResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Link  *Link                  `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	// False when an existing link was reused.
+	Created       bool `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenResponse) Reset() {
+	*x = ShortenResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenResponse) ProtoMessage() {}
+
+func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
+func (*ShortenResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ShortenResponse) GetLink() *Link {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *ShortenResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+type BulkShortenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*ShortenRequest      `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkShortenRequest) Reset() {
+	*x = BulkShortenRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkShortenRequest) ProtoMessage() {}
+
+func (x *BulkShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkShortenRequest.ProtoReflect.Descriptor instead.
+func (*BulkShortenRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BulkShortenRequest) GetRequests() []*ShortenRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// BulkShortenResult holds either the link or the error of one request, in
+// request order.
+type BulkShortenResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Response      *ShortenResponse       `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkShortenResult) Reset() {
+	*x = BulkShortenResult{}
+	mi := &file_shortli_v1_links_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkShortenResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkShortenResult) ProtoMessage() {}
+
+func (x *BulkShortenResult) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkShortenResult.ProtoReflect.Descriptor instead.
+func (*BulkShortenResult) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BulkShortenResult) GetResponse() *ShortenResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *BulkShortenResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BulkShortenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BulkShortenResult   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkShortenResponse) Reset() {
+	*x = BulkShortenResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkShortenResponse) ProtoMessage() {}
+
+func (x *BulkShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkShortenResponse.ProtoReflect.Descriptor instead.
+func (*BulkShortenResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BulkShortenResponse) GetResults() []*BulkShortenResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ResolveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRequest) ProtoMessage() {}
+
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ResolveRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+type ResolveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveResponse) ProtoMessage() {}
+
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ResolveResponse) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetStatsRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+type GetStatsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Link           *Link                  `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	TotalClicks    int64                  `protobuf:"varint,2,opt,name=total_clicks,json=totalClicks,proto3" json:"total_clicks,omitempty"`
+	BotClicks      int64                  `protobuf:"varint,3,opt,name=bot_clicks,json=botClicks,proto3" json:"bot_clicks,omitempty"`
+	UniqueVisitors int64                  `protobuf:"varint,4,opt,name=unique_visitors,json=uniqueVisitors,proto3" json:"unique_visitors,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetStatsResponse) GetLink() *Link {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetTotalClicks() int64 {
+	if x != nil {
+		return x.TotalClicks
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetBotClicks() int64 {
+	if x != nil {
+		return x.BotClicks
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetUniqueVisitors() int64 {
+	if x != nil {
+		return x.UniqueVisitors
+	}
+	return 0
+}
+
+type ListLinksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Page  int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	Limit int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Only links carrying all of these tags are listed.
+	Tags          []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLinksRequest) Reset() {
+	*x = ListLinksRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLinksRequest) ProtoMessage() {}
+
+func (x *ListLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLinksRequest.ProtoReflect.Descriptor instead.
+func (*ListLinksRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListLinksRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListLinksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListLinksRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ListLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []*Link                `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLinksResponse) Reset() {
+	*x = ListLinksResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLinksResponse) ProtoMessage() {}
+
+func (x *ListLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLinksResponse.ProtoReflect.Descriptor instead.
+func (*ListLinksResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListLinksResponse) GetLinks() []*Link {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *ListLinksResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type UpdateLinkRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	// Left unchanged when empty.
+	OriginalUrl   string   `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Preview       *Preview `protobuf:"bytes,3,opt,name=preview,proto3" json:"preview,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLinkRequest) Reset() {
+	*x = UpdateLinkRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLinkRequest) ProtoMessage() {}
+
+func (x *UpdateLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLinkRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLinkRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateLinkRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *UpdateLinkRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *UpdateLinkRequest) GetPreview() *Preview {
+	if x != nil {
+		return x.Preview
+	}
+	return nil
+}
+
+type UpdateLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          *Link                  `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLinkResponse) Reset() {
+	*x = UpdateLinkResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLinkResponse) ProtoMessage() {}
+
+func (x *UpdateLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLinkResponse.ProtoReflect.Descriptor instead.
+func (*UpdateLinkResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateLinkResponse) GetLink() *Link {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+type DeleteLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLinkRequest) Reset() {
+	*x = DeleteLinkRequest{}
+	mi := &file_shortli_v1_links_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLinkRequest) ProtoMessage() {}
+
+func (x *DeleteLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLinkRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLinkRequest) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteLinkRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+type DeleteLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLinkResponse) Reset() {
+	*x = DeleteLinkResponse{}
+	mi := &file_shortli_v1_links_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLinkResponse) ProtoMessage() {}
+
+func (x *DeleteLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shortli_v1_links_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLinkResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLinkResponse) Descriptor() ([]byte, []int) {
+	return file_shortli_v1_links_proto_rawDescGZIP(), []int{16}
+}
+
+var File_shortli_v1_links_proto protoreflect.FileDescriptor
+
+const file_shortli_v1_links_proto_rawDesc = "" +
+	"\n" +
+	"\x16shortli/v1/links.proto\x12\n" +
+	"shortli.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbc\x02\n" +
+	"\x04Link\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x02 \x01(\tR\tshortCode\x12\x1b\n" +
+	"\tshort_url\x18\x03 \x01(\tR\bshortUrl\x12!\n" +
+	"\foriginal_url\x18\x04 \x01(\tR\voriginalUrl\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x1f\n" +
+	"\vclick_count\x18\x06 \x01(\x03R\n" +
+	"clickCount\x12&\n" +
+	"\x0fbot_click_count\x18\a \x01(\x03R\rbotClickCount\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12-\n" +
+	"\apreview\x18\t \x01(\v2\x13.shortli.v1.PreviewR\apreview\"\x95\x01\n" +
+	"\aPreview\x12\x19\n" +
+	"\x05title\x18\x01 \x01(\tH\x00R\x05title\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x02 \x01(\tH\x01R\vdescription\x88\x01\x01\x12 \n" +
+	"\timage_url\x18\x03 \x01(\tH\x02R\bimageUrl\x88\x01\x01B\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_descriptionB\f\n" +
+	"\n" +
+	"_image_url\"\xb5\x01\n" +
+	"\x0eShortenRequest\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12*\n" +
+	"\x0ereuse_existing\x18\x03 \x01(\bH\x00R\rreuseExisting\x88\x01\x01\x12-\n" +
+	"\apreview\x18\x04 \x01(\v2\x13.shortli.v1.PreviewR\apreviewB\x11\n" +
+	"\x0f_reuse_existing\"Q\n" +
+	"\x0fShortenResponse\x12$\n" +
+	"\x04link\x18\x01 \x01(\v2\x10.shortli.v1.LinkR\x04link\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\"L\n" +
+	"\x12BulkShortenRequest\x126\n" +
+	"\brequests\x18\x01 \x03(\v2\x1a.shortli.v1.ShortenRequestR\brequests\"b\n" +
+	"\x11BulkShortenResult\x127\n" +
+	"\bresponse\x18\x01 \x01(\v2\x1b.shortli.v1.ShortenResponseR\bresponse\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"N\n" +
+	"\x13BulkShortenResponse\x127\n" +
+	"\aresults\x18\x01 \x03(\v2\x1d.shortli.v1.BulkShortenResultR\aresults\"/\n" +
+	"\x0eResolveRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"4\n" +
+	"\x0fResolveResponse\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\"0\n" +
+	"\x0fGetStatsRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\xa3\x01\n" +
+	"\x10GetStatsResponse\x12$\n" +
+	"\x04link\x18\x01 \x01(\v2\x10.shortli.v1.LinkR\x04link\x12!\n" +
+	"\ftotal_clicks\x18\x02 \x01(\x03R\vtotalClicks\x12\x1d\n" +
+	"\n" +
+	"bot_clicks\x18\x03 \x01(\x03R\tbotClicks\x12'\n" +
+	"\x0funique_visitors\x18\x04 \x01(\x03R\x0euniqueVisitors\"P\n" +
+	"\x10ListLinksRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\"Q\n" +
+	"\x11ListLinksResponse\x12&\n" +
+	"\x05links\x18\x01 \x03(\v2\x10.shortli.v1.LinkR\x05links\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"\x84\x01\n" +
+	"\x11UpdateLinkRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12-\n" +
+	"\apreview\x18\x03 \x01(\v2\x13.shortli.v1.PreviewR\apreview\":\n" +
+	"\x12UpdateLinkResponse\x12$\n" +
+	"\x04link\x18\x01 \x01(\v2\x10.shortli.v1.LinkR\x04link\"2\n" +
+	"\x11DeleteLinkRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x14\n" +
+	"\x12DeleteLinkResponse2\x90\x04\n" +
+	"\vLinkService\x12B\n" +
+	"\aShorten\x12\x1a.shortli.v1.ShortenRequest\x1a\x1b.shortli.v1.ShortenResponse\x12N\n" +
+	"\vBulkShorten\x12\x1e.shortli.v1.BulkShortenRequest\x1a\x1f.shortli.v1.BulkShortenResponse\x12B\n" +
+	"\aResolve\x12\x1a.shortli.v1.ResolveRequest\x1a\x1b.shortli.v1.ResolveResponse\x12E\n" +
+	"\bGetStats\x12\x1b.shortli.v1.GetStatsRequest\x1a\x1c.shortli.v1.GetStatsResponse\x12H\n" +
+	"\tListLinks\x12\x1c.shortli.v1.ListLinksRequest\x1a\x1d.shortli.v1.ListLinksResponse\x12K\n" +
+	"\n" +
+	"UpdateLink\x12\x1d.shortli.v1.UpdateLinkRequest\x1a\x1e.shortli.v1.UpdateLinkResponse\x12K\n" +
+	"\n" +
+	"DeleteLink\x12\x1d.shortli.v1.DeleteLinkRequest\x1a\x1e.shortli.v1.DeleteLinkResponseB6Z4github.com/J0es1ick/shortli/api/shortli/v1;shortliv1b\x06proto3"
+
+var (
+	file_shortli_v1_links_proto_rawDescOnce sync.Once
+	file_shortli_v1_links_proto_rawDescData []byte
+)
+
+func file_shortli_v1_links_proto_rawDescGZIP() []byte {
+	file_shortli_v1_links_proto_rawDescOnce.Do(func() {
+		file_shortli_v1_links_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_shortli_v1_links_proto_rawDesc), len(file_shortli_v1_links_proto_rawDesc)))
+	})
+	return file_shortli_v1_links_proto_rawDescData
+}
+
+var file_shortli_v1_links_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_shortli_v1_links_proto_goTypes = []any{
+	(*Link)(nil),                  // 0: shortli.v1.Link
+	(*Preview)(nil),               // 1: shortli.v1.Preview
+	(*ShortenRequest)(nil),        // 2: shortli.v1.ShortenRequest
+	(*ShortenResponse)(nil),       // 3: shortli.v1.ShortenResponse
+	(*BulkShortenRequest)(nil),    // 4: shortli.v1.BulkShortenRequest
+	(*BulkShortenResult)(nil),     // 5: shortli.v1.BulkShortenResult
+	(*BulkShortenResponse)(nil),   // 6: shortli.v1.BulkShortenResponse
+	(*ResolveRequest)(nil),        // 7: shortli.v1.ResolveRequest
+	(*ResolveResponse)(nil),       // 8: shortli.v1.ResolveResponse
+	(*GetStatsRequest)(nil),       // 9: shortli.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),      // 10: shortli.v1.GetStatsResponse
+	(*ListLinksRequest)(nil),      // 11: shortli.v1.ListLinksRequest
+	(*ListLinksResponse)(nil),     // 12: shortli.v1.ListLinksResponse
+	(*UpdateLinkRequest)(nil),     // 13: shortli.v1.UpdateLinkRequest
+	(*UpdateLinkResponse)(nil),    // 14: shortli.v1.UpdateLinkResponse
+	(*DeleteLinkRequest)(nil),     // 15: shortli.v1.DeleteLinkRequest
+	(*DeleteLinkResponse)(nil),    // 16: shortli.v1.DeleteLinkResponse
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
+}
+var file_shortli_v1_links_proto_depIdxs = []int32{
+	17, // 0: shortli.v1.Link.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 1: shortli.v1.Link.preview:type_name -> shortli.v1.Preview
+	1,  // 2: shortli.v1.ShortenRequest.preview:type_name -> shortli.v1.Preview
+	0,  // 3: shortli.v1.ShortenResponse.link:type_name -> shortli.v1.Link
+	2,  // 4: shortli.v1.BulkShortenRequest.requests:type_name -> shortli.v1.ShortenRequest
+	3,  // 5: shortli.v1.BulkShortenResult.response:type_name -> shortli.v1.ShortenResponse
+	5,  // 6: shortli.v1.BulkShortenResponse.results:type_name -> shortli.v1.BulkShortenResult
+	0,  // 7: shortli.v1.GetStatsResponse.link:type_name -> shortli.v1.Link
+	0,  // 8: shortli.v1.ListLinksResponse.links:type_name -> shortli.v1.Link
+	1,  // 9: shortli.v1.UpdateLinkRequest.preview:type_name -> shortli.v1.Preview
+	0,  // 10: shortli.v1.UpdateLinkResponse.link:type_name -> shortli.v1.Link
+	2,  // 11: shortli.v1.LinkService.Shorten:input_type -> shortli.v1.ShortenRequest
+	4,  // 12: shortli.v1.LinkService.BulkShorten:input_type -> shortli.v1.BulkShortenRequest
+	7,  // 13: shortli.v1.LinkService.Resolve:input_type -> shortli.v1.ResolveRequest
+	9,  // 14: shortli.v1.LinkService.GetStats:input_type -> shortli.v1.GetStatsRequest
+	11, // 15: shortli.v1.LinkService.ListLinks:input_type -> shortli.v1.ListLinksRequest
+	13, // 16: shortli.v1.LinkService.UpdateLink:input_type -> shortli.v1.UpdateLinkRequest
+	15, // 17: shortli.v1.LinkService.DeleteLink:input_type -> shortli.v1.DeleteLinkRequest
+	3,  // 18: shortli.v1.LinkService.Shorten:output_type -> shortli.v1.ShortenResponse
+	6,  // 19: shortli.v1.LinkService.BulkShorten:output_type -> shortli.v1.BulkShortenResponse
+	8,  // 20: shortli.v1.LinkService.Resolve:output_type -> shortli.v1.ResolveResponse
+	10, // 21: shortli.v1.LinkService.GetStats:output_type -> shortli.v1.GetStatsResponse
+	12, // 22: shortli.v1.LinkService.ListLinks:output_type -> shortli.v1.ListLinksResponse
+	14, // 23: shortli.v1.LinkService.UpdateLink:output_type -> shortli.v1.UpdateLinkResponse
+	16, // 24: shortli.v1.LinkService.DeleteLink:output_type -> shortli.v1.DeleteLinkResponse
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_shortli_v1_links_proto_init() }
+func file_shortli_v1_links_proto_init() {
+	if File_shortli_v1_links_proto != nil {
+		return
+	}
+	file_shortli_v1_links_proto_msgTypes[1].OneofWrappers = []any{}
+	file_shortli_v1_links_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shortli_v1_links_proto_rawDesc), len(file_shortli_v1_links_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_shortli_v1_links_proto_goTypes,
+		DependencyIndexes: file_shortli_v1_links_proto_depIdxs,
+		MessageInfos:      file_shortli_v1_links_proto_msgTypes,
+	}.Build()
+	File_shortli_v1_links_proto = out.File
+	file_shortli_v1_links_proto_goTypes = nil
+	file_shortli_v1_links_proto_depIdxs = nil
+}
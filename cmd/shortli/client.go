@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxRetries bounds the retries of a rate limited request.
+const maxRetries = 4
+
+type client struct {
+	cfg  *config
+	http *http.Client
+}
+
+func newClient(cfg *config) *client {
+	return &client{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	Status    int
+	Message   string
+	RequestID string
+}
+
+func (e *apiError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (HTTP %d, request %s)", e.Message, e.Status, e.RequestID)
+	}
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// do sends a request and decodes the JSON response into out, if not nil.
+// Rate limited requests are retried with backoff so that batches can run
+// unattended.
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) (int, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return 0, err
+		}
+	}
+
+	target := c.cfg.URL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("X-API-Key", c.cfg.APIKey)
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.cfg.Workspace != "" {
+			req.Header.Set("X-Workspace-ID", c.cfg.Workspace)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return 0, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			resp.Body.Close()
+
+			wait := backoff
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+			backoff *= 2
+
+			select {
+			case <-time.After(wait):
+				continue
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+
+		return resp.StatusCode, decode(resp, out)
+	}
+}
+
+func decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error     string `json:"error"`
+			RequestID string `json:"request_id"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if json.Unmarshal(data, &body) != nil || body.Error == "" {
+			body.Error = http.StatusText(resp.StatusCode)
+		}
+		return &apiError{Status: resp.StatusCode, Message: body.Error, RequestID: body.RequestID}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %v", err)
+	}
+	return nil
+}
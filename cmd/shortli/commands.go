@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+type shortenRequest struct {
+	OriginalURL   string   `json:"original_url"`
+	Tags          []string `json:"tags,omitempty"`
+	ReuseExisting *bool    `json:"reuse_existing,omitempty"`
+}
+
+// shortened is the outcome of shortening one input.
+type shortened struct {
+	Input       string   `json:"input"`
+	OriginalURL string   `json:"original_url,omitempty"`
+	ShortCode   string   `json:"short_code,omitempty"`
+	ShortURL    string   `json:"short_url,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Created is false when an existing link was reused.
+	Created bool   `json:"created"`
+	Error   string `json:"error,omitempty"`
+}
+
+type statsResponse struct {
+	models.URL
+	TotalClicks    int   `json:"total_clicks"`
+	BotClicks      int   `json:"bot_clicks"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+type listResponse struct {
+	Data []models.URL `json:"data"`
+	Meta struct {
+		Total      int `json:"total"`
+		Page       int `json:"page"`
+		Limit      int `json:"limit"`
+		TotalPages int `json:"totalPages"`
+	} `json:"meta"`
+}
+
+// runShorten shortens the URLs given as arguments or read from stdin. In
+// JSON mode every input produces one line, so batches can be processed as
+// they run. Failed inputs don't stop the batch but make the exit status 1.
+func runShorten(args []string) {
+	fs, common := newFlagSet("shorten", "[url ...]")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "tag to add to the links (repeatable)")
+	fresh := fs.Bool("new", false, "always create a new link instead of reusing an existing one")
+	args = parse(fs, args)
+
+	cfg := common.load()
+	c := newClient(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var reuse *bool
+	if *fresh {
+		reuse = new(bool)
+	}
+
+	failed := 0
+	shorten := func(input string) {
+		var resp struct {
+			OriginalURL string   `json:"original_url"`
+			ShortCode   string   `json:"short_code"`
+			Tags        []string `json:"tags"`
+		}
+		status, err := c.do(ctx, http.MethodPost, "/api/shorten", nil, shortenRequest{
+			OriginalURL:   input,
+			Tags:          tags,
+			ReuseExisting: reuse,
+		}, &resp)
+
+		result := shortened{Input: input}
+		if err != nil {
+			failed++
+			result.Error = err.Error()
+		} else {
+			result.OriginalURL = resp.OriginalURL
+			result.ShortCode = resp.ShortCode
+			result.ShortURL = c.shortURL(resp.ShortCode)
+			result.Tags = resp.Tags
+			result.Created = status == http.StatusCreated
+		}
+		printShortened(cfg, result)
+	}
+
+	if len(args) == 0 || (len(args) == 1 && args[0] == "-") {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() && ctx.Err() == nil {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			shorten(line)
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatalf("reading stdin: %v", err)
+		}
+	} else {
+		for _, arg := range args {
+			if ctx.Err() != nil {
+				break
+			}
+			shorten(arg)
+		}
+	}
+
+	if ctx.Err() != nil {
+		log.Fatal("interrupted")
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printShortened(cfg *config, result shortened) {
+	if cfg.Output == outputJSON {
+		line, _ := json.Marshal(result)
+		fmt.Println(string(line))
+		return
+	}
+
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", result.Input, result.Error)
+		return
+	}
+	fmt.Printf("%s\t%s\n", result.ShortURL, result.OriginalURL)
+}
+
+func runStats(args []string) {
+	fs, common := newFlagSet("stats", "<code>")
+	args = parse(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := common.load()
+	c := newClient(cfg)
+
+	var stats statsResponse
+	if _, err := c.do(context.Background(), http.MethodGet, "/api/stats/"+url.PathEscape(args[0]), nil, nil, &stats); err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg.Output == outputJSON {
+		printJSON(stats)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Short URL:\t%s\n", c.shortURL(stats.ShortCode))
+	fmt.Fprintf(w, "Destination:\t%s\n", stats.OriginalURL)
+	fmt.Fprintf(w, "Clicks:\t%d\n", stats.TotalClicks)
+	fmt.Fprintf(w, "Bot clicks:\t%d\n", stats.BotClicks)
+	fmt.Fprintf(w, "Unique visitors:\t~%d\n", stats.UniqueVisitors)
+	fmt.Fprintf(w, "Created:\t%s\n", stats.CreatedAt.Local().Format(time.RFC1123))
+	if len(stats.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(stats.Tags, ", "))
+	}
+	if stats.Metadata != nil && stats.Metadata.Title != nil {
+		fmt.Fprintf(w, "Title:\t%s\n", *stats.Metadata.Title)
+	}
+	w.Flush()
+}
+
+func runList(args []string) {
+	fs, common := newFlagSet("ls", "")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "only list links with this tag (repeatable, all must match)")
+	page := fs.Int("page", 1, "page to list")
+	limit := fs.Int("limit", 20, "links per page, at most 100")
+	all := fs.Bool("all", false, "list every page")
+	if args := parse(fs, args); len(args) != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *limit < 1 || *limit > 100 {
+		log.Fatal("-limit must be between 1 and 100")
+	}
+
+	cfg := common.load()
+	c := newClient(cfg)
+
+	links := []models.URL{}
+	for p := *page; ; p++ {
+		query := url.Values{
+			"page":  {strconv.Itoa(p)},
+			"limit": {strconv.Itoa(*limit)},
+			"tag":   tags,
+		}
+
+		var resp listResponse
+		if _, err := c.do(context.Background(), http.MethodGet, "/api/stats", query, nil, &resp); err != nil {
+			log.Fatal(err)
+		}
+		links = append(links, resp.Data...)
+
+		if !*all || p >= resp.Meta.TotalPages {
+			break
+		}
+	}
+
+	if cfg.Output == outputJSON {
+		printJSON(links)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CODE\tCLICKS\tCREATED\tTAGS\tDESTINATION")
+	for _, link := range links {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			link.ShortCode,
+			link.ClickCount,
+			link.CreatedAt.Local().Format("2006-01-02"),
+			strings.Join(link.Tags, ","),
+			link.OriginalURL,
+		)
+	}
+	w.Flush()
+}
+
+func runRemove(args []string) {
+	fs, common := newFlagSet("rm", "<code> ...")
+	args = parse(fs, args)
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cfg := common.load()
+	c := newClient(cfg)
+
+	failed := false
+	for _, code := range args {
+		_, err := c.do(context.Background(), http.MethodDelete, "/urls/"+url.PathEscape(code), nil, nil, nil)
+		if err != nil {
+			failed = true
+			log.Printf("%s: %v", code, err)
+			continue
+		}
+
+		if cfg.Output == outputJSON {
+			line, _ := json.Marshal(map[string]interface{}{"short_code": code, "deleted": true})
+			fmt.Println(string(line))
+		} else {
+			fmt.Printf("Moved %s to the trash\n", code)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// shortURL builds the short link from the API endpoint, which also serves
+// the redirects.
+func (c *client) shortURL(code string) string {
+	return c.cfg.URL + "/" + code
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
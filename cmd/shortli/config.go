@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type config struct {
+	URL       string
+	APIKey    string
+	Workspace string
+	Output    string
+}
+
+// commonFlags are accepted by every command and take precedence over the
+// config file and the environment.
+type commonFlags struct {
+	config    *string
+	url       *string
+	apiKey    *string
+	workspace *string
+	output    *string
+}
+
+func newFlagSet(name, args string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: shortli %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs, &commonFlags{
+		config:    fs.String("config", "", "config file"),
+		url:       fs.String("url", "", "API endpoint"),
+		apiKey:    fs.String("api-key", "", "API key"),
+		workspace: fs.String("workspace", "", "workspace to act in"),
+		output:    fs.String("o", "", "output format: table or json"),
+	}
+}
+
+// parse parses args, allowing flags after positional arguments, and returns
+// the positional arguments.
+func parse(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional
+		}
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...)
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// load resolves the configuration from the config file, the environment and
+// the flags, in increasing order of precedence.
+func (f *commonFlags) load() *config {
+	v := viper.New()
+	v.SetDefault("url", "http://localhost:8080")
+	v.SetDefault("output", outputTable)
+	v.SetEnvPrefix("SHORTLI")
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+
+	path := *f.config
+	if path == "" {
+		path = os.Getenv("SHORTLI_CONFIG")
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			log.Fatalf("reading config: %v", err)
+		}
+	} else if dir, err := os.UserConfigDir(); err == nil {
+		v.SetConfigFile(filepath.Join(dir, "shortli", "config.yaml"))
+		if err := v.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatalf("reading config: %v", err)
+		}
+	}
+
+	cfg := &config{
+		URL:       v.GetString("url"),
+		APIKey:    v.GetString("api_key"),
+		Workspace: v.GetString("workspace"),
+		Output:    v.GetString("output"),
+	}
+	override(&cfg.URL, *f.url)
+	override(&cfg.APIKey, *f.apiKey)
+	override(&cfg.Workspace, *f.workspace)
+	override(&cfg.Output, *f.output)
+
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.APIKey == "" {
+		log.Fatal("no API key: set api_key in the config file, SHORTLI_API_KEY or -api-key")
+	}
+	if cfg.Output != outputTable && cfg.Output != outputJSON {
+		log.Fatalf("unknown output format %q, use table or json", cfg.Output)
+	}
+
+	return cfg
+}
+
+func override(value *string, flagValue string) {
+	if flagValue != "" {
+		*value = flagValue
+	}
+}
+
+// stringsFlag collects the values of a repeatable flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,62 @@
+// Command shortli is a command-line client for the shortli REST API.
+//
+// The API endpoint, key and workspace are read from the config file
+// (default $XDG_CONFIG_HOME/shortli/config.yaml), then from SHORTLI_*
+// environment variables, then from flags:
+//
+//	url: https://sho.rt
+//	api_key: sk_...
+//	workspace: 3
+//	output: table
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+const usage = `usage: shortli <command> [flags] [args]
+
+Commands:
+  shorten [url ...]   shorten URLs; reads one URL per line from stdin when none are given or the only one is "-"
+  stats <code>        show the stats of a link
+  ls                  list links
+  rm <code> ...       move links to the trash
+
+Global flags:
+  -config file        config file (env SHORTLI_CONFIG)
+  -url url            API endpoint (env SHORTLI_URL, default http://localhost:8080)
+  -api-key key        API key (env SHORTLI_API_KEY)
+  -workspace id       workspace to act in (env SHORTLI_WORKSPACE)
+  -o table|json       output format (env SHORTLI_OUTPUT, default table)
+
+Run "shortli <command> -h" for the flags of a command.
+`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("shortli: ")
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "shorten":
+		runShorten(args)
+	case "stats":
+		runStats(args)
+	case "ls", "list":
+		runList(args)
+	case "rm", "delete":
+		runRemove(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "shortli: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// runAPIKey implements `shortliService apikey issue|list|revoke`.
+func runAPIKey(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: shortliService apikey issue|list|revoke [flags]")
+	}
+
+	switch args[0] {
+	case "issue":
+		fs := flag.NewFlagSet("apikey issue", flag.ExitOnError)
+		user := fs.String("user", "", "id or email of the key owner")
+		name := fs.String("name", "default", "name of the key")
+		fs.Parse(args[1:])
+
+		_, db := openDatabase()
+		defer db.Close()
+		userRepo := repository.NewUserRepository(db.DB)
+
+		owner := findUser(userRepo, *user)
+		token, err := issueAPIKey(userRepo, owner.ID, *name)
+		if err != nil {
+			log.Fatalf("Failed to issue API key: %v", err)
+		}
+
+		fmt.Printf("User:    %d (%s)\n", owner.ID, owner.Email)
+		fmt.Printf("API key: %s\n", token)
+
+	case "list":
+		fs := flag.NewFlagSet("apikey list", flag.ExitOnError)
+		user := fs.String("user", "", "id or email of the key owner")
+		fs.Parse(args[1:])
+
+		_, db := openDatabase()
+		defer db.Close()
+		userRepo := repository.NewUserRepository(db.DB)
+
+		keys, err := userRepo.FindAPIKeysByUser(findUser(userRepo, *user).ID)
+		if err != nil {
+			log.Fatalf("Failed to list API keys: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tCREATED\tLAST USED\tREVOKED")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Prefix,
+				key.CreatedAt.Format(time.RFC3339), formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+		}
+		w.Flush()
+
+	case "revoke":
+		fs := flag.NewFlagSet("apikey revoke", flag.ExitOnError)
+		fs.Parse(args[1:])
+
+		id, err := strconv.Atoi(fs.Arg(0))
+		if fs.NArg() != 1 || err != nil {
+			log.Fatal("usage: shortliService apikey revoke <key id>")
+		}
+
+		_, db := openDatabase()
+		defer db.Close()
+
+		if err := repository.NewUserRepository(db.DB).RevokeAPIKey(id); err != nil {
+			log.Fatalf("Failed to revoke API key: %v", err)
+		}
+		fmt.Printf("Revoked API key %d\n", id)
+
+	default:
+		log.Fatal("usage: shortliService apikey issue|list|revoke [flags]")
+	}
+}
+
+// issueAPIKey stores a new key for userID and returns the token, which is
+// only ever shown once.
+func issueAPIKey(userRepo *repository.UserRepository, userID int, name string) (string, error) {
+	token, hash, err := auth.GenerateToken("sk_")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = userRepo.SaveAPIKey(&models.APIKey{
+		UserId:    userID,
+		Name:      name,
+		Prefix:    token[:8],
+		KeyHash:   hash,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// findUser resolves a user by id or email.
+func findUser(userRepo *repository.UserRepository, ref string) *models.User {
+	if ref == "" {
+		log.Fatal("-user is required")
+	}
+
+	var user *models.User
+	var err error
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		user, err = userRepo.FindUserByID(id)
+	} else {
+		user, err = userRepo.FindUserByEmail(ref)
+	}
+	if err != nil {
+		log.Fatalf("Failed to find user %s: %v", ref, err)
+	}
+
+	return user
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/tasks"
+	"github.com/J0es1ick/shortli/internal/app/webhooks"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// runCleanup implements `shortliService cleanup`, running the cleanup task
+// once. Webhook deliveries for expired links are queued for the server to
+// send.
+func runCleanup(args []string) {
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would be archived and purged")
+	fs.Parse(args)
+
+	cfg, db := openDatabase()
+	defer db.Close()
+
+	dispatcher := webhooks.NewDispatcher(repository.NewWebhookRepository(db.DB), newOutboundClient(10*time.Second), 10*time.Second)
+	defer dispatcher.Close()
+	task := tasks.NewCleanupTask(repository.NewUrlRepository(db.DB), dispatcher, 24*time.Hour, time.Duration(cfg.TrashRetentionDays)*24*time.Hour)
+
+	if *dryRun {
+		result, err := task.DryRun(context.Background())
+		if err != nil {
+			log.Fatalf("Cleanup dry run failed: %v", err)
+		}
+		fmt.Printf("Would archive %d links and purge %d links from the trash\n", result.Archived, result.Purged)
+		return
+	}
+
+	result, err := task.RunOnce(context.Background())
+	if err != nil {
+		log.Fatalf("Cleanup failed: %v", err)
+	}
+	fmt.Printf("Archived %d links and purged %d links from the trash\n", result.Archived, result.Purged)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/health"
+	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/internal/database"
+	"github.com/J0es1ick/shortli/internal/logger"
+)
+
+// runConfig implements `shortliService config check`, which validates the
+// configuration and the connection to its dependencies without starting
+// the server. It exits with status 1 if any check fails.
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "check" {
+		log.Fatal("usage: shortliService config check")
+	}
+
+	fs := flag.NewFlagSet("config check", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 5*time.Second, "timeout of each connectivity check")
+	fs.StringVar(&configFile, "config", configFile, "config file (YAML, TOML, JSON or .env)")
+	fs.Parse(args[1:])
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		var invalid *config.InvalidError
+		if errors.As(err, &invalid) {
+			for _, problem := range invalid.Problems {
+				fmt.Printf("FAIL  config: %s\n", problem)
+			}
+		} else {
+			fmt.Printf("FAIL  config: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	failed := false
+	report := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("FAIL  %s: %v\n", name, err)
+			return
+		}
+		fmt.Printf("ok    %s\n", name)
+	}
+
+	report("config", nil)
+
+	_, err = logger.New(io.Discard, cfg.LogFormat, cfg.LogLevel)
+	report("logging", err)
+
+	_, err = newValidator(cfg.Screening)
+	report("url screening", err)
+
+	_, err = newClassifier(cfg.BotFilter)
+	report("bot filtering", err)
+
+	check := func(name string, fn func(context.Context) error) {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		report(name, fn(ctx))
+	}
+
+	db, err := database.DBInit(cfg)
+	report("database", err)
+	if err == nil {
+		defer db.Close()
+		check("migrations", db.CheckMigrations)
+	}
+
+	if cfg.RedisURL != "" {
+		check("cache", health.RedisCheck(cfg.RedisURL))
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/J0es1ick/shortli/internal/app/export"
+	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/internal/database"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// runExport implements `shortliService export`, writing the same CSV/NDJSON
+// streams as the /api/export endpoints to a file or stdout.
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	kind := fs.String("type", "links", "what to export: links or clicks")
+	format := fs.String("format", export.FormatCSV, "output format: csv or ndjson")
+	workspaceID := fs.Int("workspace", 0, "only export data of this workspace (default all)")
+	userID := fs.String("user", "", "only export data owned by this user id")
+	from := fs.String("from", "", "start of the date range (YYYY-MM-DD or RFC 3339)")
+	to := fs.String("to", "", "end of the date range, exclusive")
+	tags := fs.String("tags", "", "comma separated tags the links must carry")
+	output := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	if err := export.ValidateFormat(*format); err != nil {
+		log.Fatal(err)
+	}
+
+	var tagList []string
+	if *tags != "" {
+		tagList = strings.Split(*tags, ",")
+	}
+
+	filter, err := export.ParseFilter(*userID, *from, *to, tagList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *workspaceID != 0 {
+		filter.WorkspaceID = workspaceID
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Fatalf("Config initialization error: %v", err)
+	}
+
+	db, err := database.DBInit(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		defer out.Close()
+	}
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	ctx := context.Background()
+	switch *kind {
+	case "links":
+		err = export.Links(ctx, repository.NewUrlRepository(db.DB), filter, *format, w)
+	case "clicks":
+		err = export.Clicks(ctx, repository.NewClickRepository(db.DB), filter, *format, w)
+	default:
+		err = fmt.Errorf("unknown export type %q", *kind)
+	}
+	if err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/importer"
+	"github.com/J0es1ick/shortli/internal/app/service"
+	"github.com/J0es1ick/shortli/internal/app/stream"
+	"github.com/J0es1ick/shortli/internal/app/visitors"
+	"github.com/J0es1ick/shortli/internal/app/webhooks"
+	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/internal/database"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// runImport implements `shortliService import <file>`, loading a Bitly or
+// YOURLS export and printing the resulting report as JSON. link.created
+// webhooks and metadata fetches of the imported links are queued for the
+// server to carry out.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "input format: csv or json (default: from file extension)")
+	workspaceID := fs.Int("workspace", 1, "workspace that will own the imported links")
+	userID := fs.Int("user", 0, "user id recorded as the creator of the imported links")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without writing anything")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		log.Fatal("usage: shortliService import [flags] <file>")
+	}
+	path := fs.Arg(0)
+
+	if *format == "" {
+		*format = importer.DetectFormat(path, "")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open import file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := importer.Parse(*format, file)
+	if err != nil {
+		log.Fatalf("Failed to parse import file: %v", err)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Fatalf("Config initialization error: %v", err)
+	}
+
+	db, err := database.DBInit(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	urlValidator, err := newValidator(cfg.Screening)
+	if err != nil {
+		log.Fatalf("Failed to initialize URL screening: %v", err)
+	}
+
+	urlRepo := repository.NewUrlRepository(db.DB)
+	metadataRepo := repository.NewMetadataRepository(db.DB)
+	dispatcher := webhooks.NewDispatcher(repository.NewWebhookRepository(db.DB), newOutboundClient(10*time.Second), 10*time.Second)
+	defer dispatcher.Close()
+	links := service.NewLinkService(urlRepo, repository.NewClickRepository(db.DB), metadataRepo, dispatcher, urlValidator,
+		pendingMetadata{metadataRepo}, visitors.NewTracker(repository.NewVisitorRepository(db.DB), time.Minute), stream.NewHub())
+
+	report, err := importer.NewImporter(urlRepo, links, urlValidator).Import(context.Background(), records, *workspaceID, *userID, *dryRun)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
+
+// pendingMetadata marks links as awaiting a metadata fetch without fetching
+// them, leaving the work to the sweep of a running server.
+type pendingMetadata struct {
+	metadataRepository *repository.MetadataRepository
+}
+
+func (p pendingMetadata) Request(ctx context.Context, url *models.URL) {
+	if err := p.metadataRepository.RequestFetch(ctx, url.ID); err != nil {
+		log.Printf("Failed to request metadata of %s: %v", url.ShortCode, err)
+	}
+}
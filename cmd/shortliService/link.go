@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/webhooks"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// runLink implements `shortliService link disable|enable <code>`. A
+// disabled link answers 410 Gone in every workspace until it is enabled
+// again, and unlike the trash its owners can't undo it.
+func runLink(args []string) {
+	if len(args) == 0 || (args[0] != "disable" && args[0] != "enable") {
+		log.Fatal("usage: shortliService link disable [-reason <text>] <code> | link enable <code>")
+	}
+	disable := args[0] == "disable"
+
+	fs := flag.NewFlagSet("link "+args[0], flag.ExitOnError)
+	reason := fs.String("reason", "", "why the link is disabled, kept for operators")
+	fs.Parse(args[1:])
+
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: shortliService link %s <code>", args[0])
+	}
+	if !disable && *reason != "" {
+		log.Fatal("-reason only applies to link disable")
+	}
+
+	_, db := openDatabase()
+	defer db.Close()
+
+	url, err := repository.NewUrlRepository(db.DB).SetUrlDisabled(context.Background(), fs.Arg(0), disable, *reason)
+	if err != nil {
+		log.Fatalf("Failed to update link: %v", err)
+	}
+
+	event := models.EventLinkEnabled
+	if disable {
+		event = models.EventLinkDisabled
+	}
+	dispatcher := webhooks.NewDispatcher(repository.NewWebhookRepository(db.DB), newOutboundClient(10*time.Second), 10*time.Second)
+	defer dispatcher.Close()
+	dispatcher.Publish(url.WorkspaceID, event, url)
+
+	if disable {
+		fmt.Printf("Disabled %s (workspace %d, %s)\n", url.ShortCode, url.WorkspaceID, url.OriginalURL)
+	} else {
+		fmt.Printf("Enabled %s (workspace %d, %s)\n", url.ShortCode, url.WorkspaceID, url.OriginalURL)
+	}
+}
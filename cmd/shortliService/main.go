@@ -1,73 +1,94 @@
 package main
 
 import (
-	"context"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	"github.com/J0es1ick/shortli/internal/app/middleware"
-	"github.com/J0es1ick/shortli/internal/app/routes"
-	"github.com/J0es1ick/shortli/internal/app/tasks"
 	"github.com/J0es1ick/shortli/internal/config"
 	"github.com/J0es1ick/shortli/internal/database"
-	"github.com/J0es1ick/shortli/internal/repository"
 )
 
-func main() {
-	cfg, err := config.InitConfig()
-	if err != nil {
-		log.Fatalf("Config initialization error: %v", err)
-	}
+const usage = `usage: shortliService [-config file] [command] [flags]
 
-	fmt.Printf("Server port: %s\n", cfg.ServerPort)
-	fmt.Printf("DB host: %s\n", cfg.Database.Host)
+Commands:
+  serve                       run the server (default)
+  migrate                     apply pending database migrations
+  cleanup [-dry-run]          archive expired links and purge the trash
+  config check                validate the configuration and database connectivity
+  user create                 create a user and print an API key
+  apikey issue|list|revoke    manage API keys
+  link disable|enable <code>  take a link down or bring it back
+  export                      export links or clicks
+  import <file>               import a Bitly or YOURLS export
 
-	db, err := database.DBInit(cfg)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-	fmt.Println("Connection successful")
-	defer db.Close()
+Configuration is read from environment variables and the optional -config
+file (YAML, TOML, JSON or .env); see .env.example for every variable.
 
-	urlRepo := repository.NewUrlRepository(db.DB)
-	handler := routes.SetupRoutes(cfg, urlRepo)
+Run "shortliService <command> -h" for the flags of a command.
+`
 
-	cleanupTask := tasks.NewCleanupTask(urlRepo, 24*time.Hour)
-	go cleanupTask.Start()
+// configFile is the -config file; commands load it through config.Load.
+var configFile string
 
-	rateLimiter := middleware.NewRateLimiter(100, time.Minute) 
-    handler = rateLimiter.Middleware(handler)
+func main() {
+	fs := flag.NewFlagSet("shortliService", flag.ExitOnError)
+	fs.StringVar(&configFile, "config", "", "config file (YAML, TOML, JSON or .env)")
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage) }
+	fs.Parse(os.Args[1:])
 
-	server := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
-		Handler: handler,
+	if fs.NArg() == 0 {
+		runServe(nil)
+		return
 	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		log.Printf("Server starting on port %s", cfg.ServerPort)
-		if err := server.ListenAndServe(); err != nil {
-			log.Printf("Server failed: %v", err)
-			quit <- syscall.SIGTERM
-		}
-	}()
-
-	<- quit
-	log.Println("Shutting down server")
+	args := fs.Args()[1:]
+	switch fs.Arg(0) {
+	case "serve":
+		runServe(args)
+	case "migrate":
+		runMigrate(args)
+	case "cleanup":
+		runCleanup(args)
+	case "config":
+		runConfig(args)
+	case "export":
+		runExport(args)
+	case "import":
+		runImport(args)
+	case "user":
+		runUser(args)
+	case "apikey":
+		runAPIKey(args)
+	case "link":
+		runLink(args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", fs.Arg(0), usage)
+		os.Exit(2)
+	}
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// openDatabase loads the configuration and connects to the database for the
+// administrative commands.
+func openDatabase() (*config.Config, *database.Database) {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Fatalf("Config initialization error: %v", err)
+	}
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Server shutdown error: %v", err)
+	db, err := database.DBInit(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	log.Println("Server gracefully stopped")
-}
\ No newline at end of file
+	return cfg, db
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
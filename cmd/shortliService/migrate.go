@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// runMigrate implements `shortliService migrate`, applying pending
+// migrations without starting the server.
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Parse(args)
+
+	_, db := openDatabase()
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		log.Fatalf("Failed to apply migrations: %v", err)
+	}
+
+	version, _, err := db.MigrationVersion(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to read migration version: %v", err)
+	}
+	fmt.Printf("Schema at version %d\n", version)
+}
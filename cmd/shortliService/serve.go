@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/analytics"
+	"github.com/J0es1ick/shortli/internal/app/grpcserver"
+	"github.com/J0es1ick/shortli/internal/app/health"
+	"github.com/J0es1ick/shortli/internal/app/metadata"
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/app/middleware"
+	"github.com/J0es1ick/shortli/internal/app/routes"
+	"github.com/J0es1ick/shortli/internal/app/service"
+	"github.com/J0es1ick/shortli/internal/app/stream"
+	"github.com/J0es1ick/shortli/internal/app/tasks"
+	"github.com/J0es1ick/shortli/internal/app/visitors"
+	"github.com/J0es1ick/shortli/internal/app/webhooks"
+	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/internal/database"
+	"github.com/J0es1ick/shortli/internal/logger"
+	"github.com/J0es1ick/shortli/internal/repository"
+	"github.com/J0es1ick/shortli/internal/tracing"
+)
+
+// runServe implements `shortliService serve`, the default command: it
+// applies pending migrations and runs the HTTP and gRPC servers and the
+// background workers until SIGINT or SIGTERM.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&configFile, "config", configFile, "config file (YAML, TOML, JSON or .env)")
+	fs.Parse(args)
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		log.Fatalf("Config initialization error: %v", err)
+	}
+
+	appLogger, err := logger.New(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("Logger initialization error: %v", err)
+	}
+	slog.SetDefault(appLogger)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Failed to initialize tracing", err)
+	}
+
+	db, err := database.DBInit(cfg)
+	if err != nil {
+		fatal("Failed to initialize database", err)
+	}
+	slog.Info("Database connection established", "host", cfg.Database.Host, "database", cfg.Database.Name)
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		fatal("Failed to apply migrations", err)
+	}
+
+	metrics.RegisterDB(db.DB.DB, cfg.Database.Name)
+
+	urlValidator, err := newValidator(cfg.Screening)
+	if err != nil {
+		fatal("Failed to initialize URL screening", err)
+	}
+
+	classifier, err := newClassifier(cfg.BotFilter)
+	if err != nil {
+		fatal("Failed to initialize bot filtering", err)
+	}
+
+	urlRepo := repository.NewUrlRepository(db.DB)
+	clickRepo := repository.NewClickRepository(db.DB)
+	userRepo := repository.NewUserRepository(db.DB)
+	workspaceRepo := repository.NewWorkspaceRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+	dispatcher := webhooks.NewDispatcher(webhookRepo, newOutboundClient(10*time.Second), 10*time.Second)
+	metadataRepo := repository.NewMetadataRepository(db.DB)
+	enricher := metadata.NewEnricher(metadataRepo, metadata.NewFetcher(newOutboundClient(10*time.Second)), time.Minute)
+	visitorTracker := visitors.NewTracker(repository.NewVisitorRepository(db.DB), time.Minute)
+	cleanupTask := tasks.NewCleanupTask(urlRepo, dispatcher, 24*time.Hour, time.Duration(cfg.TrashRetentionDays)*24*time.Hour)
+
+	warnOwnerlessWorkspaces(workspaceRepo)
+
+	hub := stream.NewHub()
+	links := service.NewLinkService(urlRepo, clickRepo, metadataRepo, dispatcher, urlValidator, enricher, visitorTracker, hub)
+	rollupTask := tasks.NewRollupTask(clickRepo, 5*time.Minute)
+
+	checker := health.NewChecker()
+	checker.Add("database", db.Ping)
+	checker.Add("migrations", db.CheckMigrations)
+	checker.AddNonFatal("cleanup", func(context.Context) error { return cleanupTask.Check() })
+	checker.AddNonFatal("rollup", func(context.Context) error { return rollupTask.Check() })
+	if cfg.RedisURL != "" {
+		checker.Add("cache", health.RedisCheck(cfg.RedisURL))
+	}
+
+	handler := routes.SetupRoutes(cfg, routes.Dependencies{
+		UrlRepository:       urlRepo,
+		ClickRepository:     clickRepo,
+		WebhookRepository:   webhookRepo,
+		UserRepository:      userRepo,
+		WorkspaceRepository: workspaceRepo,
+		Health:              checker,
+		Validator:           urlValidator,
+		TargetValidator:     newTargetValidator(),
+		Links:               links,
+		Classifier:          classifier,
+		Hub:                 hub,
+		Analytics:           analytics.NewService(repository.NewAnalyticsRepository(db.DB), time.Minute),
+	})
+
+	go cleanupTask.Start()
+	go rollupTask.Start()
+	go dispatcher.Start()
+	go enricher.Start()
+	go visitorTracker.Start()
+
+	handler = tracing.RouteName(handler)
+
+	rateLimiter := middleware.NewRateLimiter(100, time.Minute) 
+    handler = rateLimiter.Middleware(handler)
+	handler = middleware.AccessLog(handler)
+	handler = metrics.Middleware(handler)
+	handler = middleware.RequestID(handler)
+	handler = tracing.Middleware(handler)
+
+	server := &http.Server{
+		Addr:    ":" + cfg.ServerPort,
+		Handler: handler,
+	}
+	// Live streams never end on their own; closing the hub ends them so
+	// that Shutdown doesn't wait for its timeout.
+	server.RegisterOnShutdown(hub.Close)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		slog.Info("Server starting", "port", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server failed", "error", err)
+			quit <- syscall.SIGTERM
+		}
+	}()
+
+	var grpcServer *grpcserver.Server
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			fatal("Failed to listen for gRPC", err)
+		}
+
+		grpcServer = grpcserver.New(cfg, links, userRepo, workspaceRepo)
+		go func() {
+			slog.Info("gRPC server starting", "port", cfg.GRPCPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				slog.Error("gRPC server failed", "error", err)
+				quit <- syscall.SIGTERM
+			}
+		}()
+	}
+
+	<- quit
+	slog.Info("Shutting down server")
+
+	// Fail readiness first and give load balancers time to notice before
+	// connections stop being accepted.
+	checker.Drain()
+	time.Sleep(time.Duration(cfg.ShutdownDrainSecs) * time.Second)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Server shutdown error", "error", err)
+	}
+
+	if grpcServer != nil {
+		grpcServer.Shutdown(shutdownCtx)
+	}
+
+	dispatcher.Close()
+
+	if err := visitorTracker.Flush(shutdownCtx); err != nil {
+		slog.Error("Visitor sketch flush error", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Tracing shutdown error", "error", err)
+	}
+
+	slog.Info("Server gracefully stopped")
+}
+
+// warnOwnerlessWorkspaces reports workspaces whose links nobody can reach,
+// which is where links created before workspaces existed end up.
+func warnOwnerlessWorkspaces(workspaceRepo *repository.WorkspaceRepository) {
+	workspaces, err := workspaceRepo.FindOwnerlessWorkspaces()
+	if err != nil {
+		slog.Error("Failed to check workspace owners", "error", err)
+		return
+	}
+
+	for _, workspace := range workspaces {
+		slog.Warn("Workspace has no owner, its links are unreachable until one is added",
+			"workspace_id", workspace.ID,
+			"name", workspace.Name,
+			"fix", fmt.Sprintf("shortliService user create -email <email> -workspace %d -role owner", workspace.ID),
+		)
+	}
+}
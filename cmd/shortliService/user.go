@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// runUser implements `shortliService user create`, the only way to create
+// accounts: it adds the user to a workspace and prints a fresh API key.
+// Joining a workspace that has no owner, such as the default workspace
+// created by migration 7, with -workspace <id> -role owner makes its links
+// reachable again.
+func runUser(args []string) {
+	if len(args) == 0 || args[0] != "create" {
+		log.Fatal("usage: shortliService user create -email <email> [flags]")
+	}
+
+	fs := flag.NewFlagSet("user create", flag.ExitOnError)
+	email := fs.String("email", "", "email address of the new user")
+	name := fs.String("name", "", "display name")
+	workspaceID := fs.Int("workspace", 0, "existing workspace to join (default: create a personal one)")
+	role := fs.String("role", models.RoleOwner, "role in the joined workspace: owner, editor or viewer")
+	fs.Parse(args[1:])
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	if !models.ValidRole(*role) {
+		log.Fatal("-role must be owner, editor or viewer")
+	}
+
+	_, db := openDatabase()
+	defer db.Close()
+
+	token, hash, err := auth.GenerateToken("sk_")
+	if err != nil {
+		log.Fatalf("Failed to generate API key: %v", err)
+	}
+
+	account := &repository.Account{
+		User:          &models.User{Email: *email, Name: *name, CreatedAt: time.Now()},
+		WorkspaceID:   *workspaceID,
+		WorkspaceName: fmt.Sprintf("%s's workspace", *email),
+		Role:          *role,
+		APIKey: &models.APIKey{
+			Name:      "default",
+			Prefix:    token[:8],
+			KeyHash:   hash,
+			CreatedAt: time.Now(),
+		},
+	}
+	if err := repository.NewUserRepository(db.DB).CreateAccount(account); err != nil {
+		log.Fatalf("Failed to create user: %v", err)
+	}
+	user := account.User
+
+	fmt.Printf("User:      %d (%s)\n", user.ID, user.Email)
+	fmt.Printf("Workspace: %d\n", account.WorkspaceID)
+	fmt.Printf("API key:   %s\n", token)
+}
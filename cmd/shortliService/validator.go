@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/pkg/useragent"
+	"github.com/J0es1ick/shortli/pkg/validator"
+)
+
+// newValidator assembles destination screening from the configuration.
+func newValidator(cfg config.Screening) (*validator.Validator, error) {
+	screeners := []validator.Screener{
+		validator.NewDomainList(cfg.DenyList, "URL domain is blocked"),
+		validator.NewShortenerList(cfg.ShortDomains),
+	}
+
+	if cfg.ThreatListFile != "" {
+		threats, err := validator.LoadThreatList(cfg.ThreatListFile)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Threat list loaded", "file", cfg.ThreatListFile, "prefixes", threats.Len())
+		screeners = append(screeners, threats)
+	}
+
+	// The network guard resolves the host, so it runs after the cheap checks.
+	screeners = append(screeners,
+		validator.NewLookalike(validator.ProtectedDomains),
+		validator.NewNetworkGuard(nil),
+	)
+
+	v := validator.New(validator.NewDomainList(cfg.AllowList, ""), screeners...)
+	v.Canonical = validator.CanonicalOptions{
+		StripFragment: cfg.StripFragment,
+		StripTracking: cfg.StripTracking,
+	}
+
+	return v, nil
+}
+
+// newClassifier assembles bot and prefetch detection from the configuration.
+func newClassifier(cfg config.BotFilter) (*useragent.Classifier, error) {
+	var scanners []netip.Prefix
+	if cfg.ScannerRangesFile != "" {
+		ranges, err := useragent.LoadScannerRanges(cfg.ScannerRangesFile)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Scanner ranges loaded", "file", cfg.ScannerRangesFile, "ranges", len(ranges))
+		scanners = ranges
+	}
+
+	return useragent.NewClassifier(cfg.UserAgents, scanners), nil
+}
+
+// newTargetValidator validates server-side fetch targets such as webhook
+// URLs, which only need to be public.
+func newTargetValidator() *validator.Validator {
+	return validator.New(nil, validator.NewNetworkGuard(nil))
+}
+
+// newOutboundClient returns an HTTP client that refuses to connect to
+// non-public addresses, whatever the target host resolves to at dial time.
+func newOutboundClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: validator.DialControl}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,117 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// Periods are the windows the overview can be computed over.
+var Periods = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+// maxCacheEntries bounds the cache; expired entries are swept once it is
+// reached.
+const maxCacheEntries = 1024
+
+type Overview struct {
+	Period       string                `json:"period"`
+	From         time.Time             `json:"from"`
+	GeneratedAt  time.Time             `json:"generated_at"`
+	Totals       models.OverviewTotals `json:"totals"`
+	TopLinks     []models.TopLink      `json:"top_links"`
+	TopReferrers []models.TopEntry     `json:"top_referrers"`
+	TopCountries []models.TopEntry     `json:"top_countries"`
+	NewLinks     []models.DailyCount   `json:"new_links"`
+}
+
+type cacheEntry struct {
+	overview *Overview
+	expires  time.Time
+}
+
+// Service computes workspace overviews and caches them for ttl, so that
+// dashboards polling the overview don't rerun its queries every time.
+type Service struct {
+	analyticsRepository *repository.AnalyticsRepository
+	ttl                 time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+func NewService(analyticsRepository *repository.AnalyticsRepository, ttl time.Duration) *Service {
+	return &Service{
+		analyticsRepository: analyticsRepository,
+		ttl:                 ttl,
+		cache:               make(map[string]cacheEntry),
+	}
+}
+
+// Overview returns the overview of workspaceID over period with the top
+// limit entries of each leaderboard. Windows start on whole UTC hours.
+func (s *Service) Overview(ctx context.Context, workspaceID int, period string, limit int) (*Overview, error) {
+	length, ok := Periods[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown period %q", period)
+	}
+
+	key := fmt.Sprintf("%d:%s:%d", workspaceID, period, limit)
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.overview, nil
+	}
+
+	from := now.Truncate(time.Hour).Add(-length)
+	today := now.Truncate(24 * time.Hour)
+	week := today.AddDate(0, 0, -6)
+
+	overview := &Overview{Period: period, From: from, GeneratedAt: now}
+
+	totals, err := s.analyticsRepository.Totals(ctx, workspaceID, today, week, from)
+	if err != nil {
+		return nil, err
+	}
+	overview.Totals = *totals
+
+	if overview.TopLinks, err = s.analyticsRepository.TopLinks(ctx, workspaceID, from, limit); err != nil {
+		return nil, err
+	}
+	if overview.TopReferrers, err = s.analyticsRepository.TopReferrers(ctx, workspaceID, from, limit); err != nil {
+		return nil, err
+	}
+	if overview.TopCountries, err = s.analyticsRepository.TopCountries(ctx, workspaceID, from, limit); err != nil {
+		return nil, err
+	}
+	if overview.NewLinks, err = s.analyticsRepository.NewLinksPerDay(ctx, workspaceID, from); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxCacheEntries {
+		for k, e := range s.cache {
+			if !now.Before(e.expires) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	if len(s.cache) < maxCacheEntries {
+		s.cache[key] = cacheEntry{overview: overview, expires: now.Add(s.ttl)}
+	}
+
+	return overview, nil
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+// Principal is the authenticated caller of a request. WorkspaceID and Role
+// are only set on routes that are scoped to a workspace.
+type Principal struct {
+	User        *models.User
+	WorkspaceID int
+	Role        string
+}
+
+func (p *Principal) UserID() int {
+	if p == nil || p.User == nil {
+		return 0
+	}
+	return p.User.ID
+}
+
+func (p *Principal) Allows(role string) bool {
+	return p != nil && models.RoleAllows(p.Role, role)
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromRequest returns the principal attached by the auth middleware. Handlers
+// mounted behind it can rely on a non-nil result.
+func FromRequest(r *http.Request) *Principal {
+	return FromContext(r.Context())
+}
+
+// FromContext is FromRequest for transports other than HTTP.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	if principal == nil {
+		return &Principal{}
+	}
+	return principal
+}
+
+// GenerateToken returns a random secret with the given prefix together with
+// the hash that is stored in place of it.
+func GenerateToken(prefix string) (token, hash string, err error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	token = prefix + hex.EncodeToString(secret)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
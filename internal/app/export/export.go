@@ -0,0 +1,215 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// flushEvery controls how many records are buffered before the output is
+// flushed, so that HTTP clients start receiving data right away.
+const flushEvery = 500
+
+var linkColumns = []string{"url_id", "short_code", "original_url", "user_id", "click_count", "tags", "created_at", "deleted_at"}
+
+var clickColumns = []string{"click_id", "url_id", "short_code", "referrer", "user_agent", "ip_address", "clicked_at", "classification", "country"}
+
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+func ValidateFormat(format string) error {
+	if format != FormatCSV && format != FormatNDJSON {
+		return fmt.Errorf("format must be %s or %s", FormatCSV, FormatNDJSON)
+	}
+	return nil
+}
+
+func Links(ctx context.Context, urlRepository *repository.UrlRepository, filter repository.ExportFilter, format string, w io.Writer) error {
+	rw, err := newRecordWriter(format, w, linkColumns)
+	if err != nil {
+		return err
+	}
+
+	err = urlRepository.StreamUrls(ctx, filter, func(url *models.URL) error {
+		deletedAt := ""
+		if url.DeletedAt != nil {
+			deletedAt = url.DeletedAt.UTC().Format(time.RFC3339)
+		}
+
+		return rw.write(url, []string{
+			strconv.Itoa(url.ID),
+			url.ShortCode,
+			url.OriginalURL,
+			strconv.Itoa(url.UserId),
+			strconv.Itoa(url.ClickCount),
+			strings.Join(url.Tags, ","),
+			url.CreatedAt.UTC().Format(time.RFC3339),
+			deletedAt,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return rw.flush()
+}
+
+func Clicks(ctx context.Context, clickRepository *repository.ClickRepository, filter repository.ExportFilter, format string, w io.Writer) error {
+	rw, err := newRecordWriter(format, w, clickColumns)
+	if err != nil {
+		return err
+	}
+
+	err = clickRepository.StreamClicks(ctx, filter, func(click *models.ClickEvent) error {
+		return rw.write(click, []string{
+			strconv.FormatInt(click.ID, 10),
+			strconv.Itoa(click.URLID),
+			click.ShortCode,
+			click.Referrer,
+			click.UserAgent,
+			click.IPAddress,
+			click.ClickedAt.UTC().Format(time.RFC3339),
+			click.Classification,
+			click.Country,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return rw.flush()
+}
+
+// recordWriter writes one record either as a CSV row or as a JSON line.
+type recordWriter struct {
+	csv     *csv.Writer
+	json    *json.Encoder
+	out     io.Writer
+	written int
+}
+
+func newRecordWriter(format string, w io.Writer, columns []string) (*recordWriter, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	rw := &recordWriter{out: w}
+	if format == FormatNDJSON {
+		rw.json = json.NewEncoder(w)
+		return rw, nil
+	}
+
+	rw.csv = csv.NewWriter(w)
+	if err := rw.csv.Write(columns); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func (rw *recordWriter) write(record interface{}, row []string) error {
+	var err error
+	if rw.json != nil {
+		err = rw.json.Encode(record)
+	} else {
+		err = rw.csv.Write(escapeRow(row))
+	}
+	if err != nil {
+		return err
+	}
+
+	rw.written++
+	if rw.written%flushEvery == 0 {
+		return rw.flush()
+	}
+	return nil
+}
+
+// escapeRow neutralizes cells that spreadsheets would evaluate as formulas,
+// such as referrers and user agents sent by visitors, by prefixing them
+// with a quote as recommended by OWASP.
+func escapeRow(row []string) []string {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cell = "'" + cell
+		}
+		escaped[i] = cell
+	}
+	return escaped
+}
+
+func (rw *recordWriter) flush() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		if err := rw.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := rw.out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// ParseFilter builds an export filter from raw parameters. Dates are accepted
+// either as RFC 3339 timestamps or as YYYY-MM-DD; empty values are ignored.
+func ParseFilter(userID, from, to string, tags []string) (repository.ExportFilter, error) {
+	var filter repository.ExportFilter
+
+	if userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid user_id")
+		}
+		filter.UserID = &id
+	}
+
+	if from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from date")
+		}
+		filter.From = &t
+	}
+
+	if to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to date")
+		}
+		filter.To = &t
+	}
+
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+
+	return filter, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	rw, err := newRecordWriter(FormatCSV, &buf, []string{"referrer", "user_agent", "clicks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := [][]string{
+		{"=HYPERLINK(\"http://evil\")", "+cmd", "3"},
+		{"-2+3", "@SUM(A1)", "0"},
+		{"\tx", "\rx", "1"},
+		{"https://example.com", "Mozilla/5.0", "12"},
+	}
+	for _, row := range rows {
+		if err := rw.write(nil, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rw.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "referrer,user_agent,clicks\n" +
+		"\"'=HYPERLINK(\"\"http://evil\"\")\",'+cmd,3\n" +
+		"'-2+3,'@SUM(A1),0\n" +
+		"'\tx,\"'\rx\",1\n" +
+		"https://example.com,Mozilla/5.0,12\n"
+	if buf.String() != want {
+		t.Errorf("got\n%q\nwant\n%q", buf.String(), want)
+	}
+}
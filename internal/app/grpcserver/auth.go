@@ -0,0 +1,96 @@
+package grpcserver
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	shortliv1 "github.com/J0es1ick/shortli/api/shortli/v1"
+)
+
+// methodRoles is the workspace role each LinkService method requires.
+// Methods of other services, such as health and reflection, are public.
+var methodRoles = map[string]string{
+	shortliv1.LinkService_Shorten_FullMethodName:     models.RoleEditor,
+	shortliv1.LinkService_BulkShorten_FullMethodName: models.RoleEditor,
+	shortliv1.LinkService_Resolve_FullMethodName:     models.RoleViewer,
+	shortliv1.LinkService_GetStats_FullMethodName:    models.RoleViewer,
+	shortliv1.LinkService_ListLinks_FullMethodName:   models.RoleViewer,
+	shortliv1.LinkService_UpdateLink_FullMethodName:  models.RoleEditor,
+	shortliv1.LinkService_DeleteLink_FullMethodName:  models.RoleEditor,
+}
+
+type authenticator struct {
+	userRepository      *repository.UserRepository
+	workspaceRepository *repository.WorkspaceRepository
+}
+
+// unary authenticates calls the same way as the HTTP auth middleware: an
+// API key in "authorization" or "x-api-key", and the workspace from
+// "x-workspace-id" or the caller's oldest membership.
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	role, ok := methodRoles[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	key := first("x-api-key")
+	if header := first("authorization"); key == "" && strings.HasPrefix(header, "Bearer ") {
+		key = strings.TrimPrefix(header, "Bearer ")
+	}
+	if key == "" {
+		return nil, status.Error(codes.Unauthenticated, "Authentication required")
+	}
+
+	user, err := a.userRepository.FindUserByAPIKey(auth.HashToken(key))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+		}
+		return nil, status.Error(codes.Internal, "Database error")
+	}
+
+	var membership *models.Membership
+	if rawID := first("x-workspace-id"); rawID != "" {
+		workspaceID, convErr := strconv.Atoi(rawID)
+		if convErr != nil {
+			return nil, status.Error(codes.InvalidArgument, "Invalid workspace id")
+		}
+		membership, err = a.workspaceRepository.FindMembership(workspaceID, user.ID)
+	} else {
+		membership, err = a.workspaceRepository.FindDefaultMembership(user.ID)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Error(codes.NotFound, "Workspace not found")
+		}
+		return nil, status.Error(codes.Internal, "Database error")
+	}
+
+	principal := &auth.Principal{
+		User:        user,
+		WorkspaceID: membership.WorkspaceID,
+		Role:        membership.Role,
+	}
+	if !principal.Allows(role) {
+		return nil, status.Error(codes.PermissionDenied, "Insufficient permissions")
+	}
+
+	return handler(auth.WithPrincipal(ctx, principal), req)
+}
@@ -0,0 +1,232 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/app/service"
+	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	shortliv1 "github.com/J0es1ick/shortli/api/shortli/v1"
+)
+
+// maxBulkShorten bounds the requests of a single BulkShorten call.
+const maxBulkShorten = 100
+
+// Server exposes the LinkService over gRPC, together with the standard
+// health and reflection services.
+type Server struct {
+	shortliv1.UnimplementedLinkServiceServer
+
+	cfg    *config.Config
+	links  *service.LinkService
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+func New(cfg *config.Config, links *service.LinkService, userRepository *repository.UserRepository, workspaceRepository *repository.WorkspaceRepository) *Server {
+	authn := &authenticator{userRepository: userRepository, workspaceRepository: workspaceRepository}
+
+	s := &Server{
+		cfg:    cfg,
+		links:  links,
+		grpc:   grpc.NewServer(grpc.ChainUnaryInterceptor(authn.unary)),
+		health: health.NewServer(),
+	}
+
+	shortliv1.RegisterLinkServiceServer(s.grpc, s)
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+
+	s.health.SetServingStatus(shortliv1.LinkService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	return s
+}
+
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Shutdown reports NOT_SERVING and waits for in-flight calls until ctx is
+// done, after which remaining calls are cancelled.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
+
+func (s *Server) Shorten(ctx context.Context, req *shortliv1.ShortenRequest) (*shortliv1.ShortenResponse, error) {
+	resp, err := s.shorten(ctx, req)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return resp, nil
+}
+
+func (s *Server) BulkShorten(ctx context.Context, req *shortliv1.BulkShortenRequest) (*shortliv1.BulkShortenResponse, error) {
+	if len(req.GetRequests()) > maxBulkShorten {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d requests per call", maxBulkShorten)
+	}
+
+	resp := &shortliv1.BulkShortenResponse{}
+	for _, item := range req.GetRequests() {
+		shortened, err := s.shorten(ctx, item)
+		if err != nil {
+			resp.Results = append(resp.Results, &shortliv1.BulkShortenResult{Error: status.Convert(toStatus(ctx, err)).Message()})
+			continue
+		}
+		resp.Results = append(resp.Results, &shortliv1.BulkShortenResult{Response: shortened})
+	}
+
+	return resp, nil
+}
+
+func (s *Server) shorten(ctx context.Context, req *shortliv1.ShortenRequest) (*shortliv1.ShortenResponse, error) {
+	result, err := s.links.Shorten(ctx, auth.FromContext(ctx), service.ShortenInput{
+		OriginalURL:   req.GetOriginalUrl(),
+		Tags:          req.GetTags(),
+		ReuseExisting: req.ReuseExisting,
+		Preview:       toPreview(req.GetPreview()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &shortliv1.ShortenResponse{Link: s.toLink(result.URL), Created: result.Created}, nil
+}
+
+func (s *Server) Resolve(ctx context.Context, req *shortliv1.ResolveRequest) (*shortliv1.ResolveResponse, error) {
+	url, err := s.links.Resolve(ctx, req.GetShortCode())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &shortliv1.ResolveResponse{OriginalUrl: url.OriginalURL}, nil
+}
+
+func (s *Server) GetStats(ctx context.Context, req *shortliv1.GetStatsRequest) (*shortliv1.GetStatsResponse, error) {
+	stats, err := s.links.Stats(ctx, auth.FromContext(ctx), req.GetShortCode())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &shortliv1.GetStatsResponse{
+		Link:           s.toLink(stats.URL),
+		TotalClicks:    int64(stats.URL.ClickCount),
+		BotClicks:      int64(stats.URL.BotClickCount),
+		UniqueVisitors: stats.UniqueVisitors,
+	}, nil
+}
+
+func (s *Server) ListLinks(ctx context.Context, req *shortliv1.ListLinksRequest) (*shortliv1.ListLinksResponse, error) {
+	page, limit := int(req.GetPage()), int(req.GetLimit())
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	links, err := s.links.List(ctx, auth.FromContext(ctx), req.GetTags(), limit, (page-1)*limit)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &shortliv1.ListLinksResponse{Total: int64(links.Total)}
+	for i := range links.Links {
+		resp.Links = append(resp.Links, s.toLink(&links.Links[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateLink(ctx context.Context, req *shortliv1.UpdateLinkRequest) (*shortliv1.UpdateLinkResponse, error) {
+	url, err := s.links.Update(ctx, auth.FromContext(ctx), req.GetShortCode(), service.UpdateInput{
+		OriginalURL: req.GetOriginalUrl(),
+		Preview:     toPreview(req.GetPreview()),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &shortliv1.UpdateLinkResponse{Link: s.toLink(url)}, nil
+}
+
+func (s *Server) DeleteLink(ctx context.Context, req *shortliv1.DeleteLinkRequest) (*shortliv1.DeleteLinkResponse, error) {
+	if _, err := s.links.Delete(ctx, auth.FromContext(ctx), req.GetShortCode()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &shortliv1.DeleteLinkResponse{}, nil
+}
+
+func (s *Server) toLink(url *models.URL) *shortliv1.Link {
+	return &shortliv1.Link{
+		Id:            int64(url.ID),
+		ShortCode:     url.ShortCode,
+		ShortUrl:      fmt.Sprintf("http://%s/%s", s.cfg.ServerPort, url.ShortCode),
+		OriginalUrl:   url.OriginalURL,
+		Tags:          url.Tags,
+		ClickCount:    int64(url.ClickCount),
+		BotClickCount: int64(url.BotClickCount),
+		CreatedAt:     timestamppb.New(url.CreatedAt),
+		Preview: &shortliv1.Preview{
+			Title:       url.OGTitle,
+			Description: url.OGDescription,
+			ImageUrl:    url.OGImageURL,
+		},
+	}
+}
+
+func toPreview(preview *shortliv1.Preview) service.Preview {
+	if preview == nil {
+		return service.Preview{}
+	}
+	return service.Preview{
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageUrl,
+	}
+}
+
+// toStatus maps LinkService errors to gRPC status codes. Unexpected errors
+// are logged and reported without their details.
+func toStatus(ctx context.Context, err error) error {
+	switch {
+	case service.IsValidation(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, "URL not found")
+	case errors.Is(err, service.ErrDisabled):
+		return status.Error(codes.FailedPrecondition, "URL disabled")
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.AlreadyExists, "URL already exists")
+	case errors.Is(err, service.ErrCodeExhausted):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	slog.ErrorContext(ctx, "gRPC call failed", "error", err)
+	return status.Error(codes.Internal, "Internal error")
+}
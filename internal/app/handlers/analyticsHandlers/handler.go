@@ -0,0 +1,61 @@
+package analyticsHandlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/J0es1ick/shortli/internal/app/analytics"
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+)
+
+const (
+	defaultPeriod = "7d"
+	defaultLimit  = 10
+	maxLimit      = 100
+)
+
+type Handler struct {
+	analytics *analytics.Service
+}
+
+func NewHandler(analytics *analytics.Service) *Handler {
+	return &Handler{
+		analytics: analytics,
+	}
+}
+
+// Overview returns workspace totals, leaderboards and new links per day
+// over the period given as 24h, 7d, 30d or 90d.
+func (h *Handler) Overview(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	period := query.Get("period")
+	if period == "" {
+		period = defaultPeriod
+	}
+	if _, ok := analytics.Periods[period]; !ok {
+		response.Error(w, http.StatusBadRequest, "period must be 24h, 7d, 30d or 90d")
+		return
+	}
+
+	limit := defaultLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxLimit {
+			response.Error(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	overview, err := h.analytics.Overview(r.Context(), auth.FromRequest(r).WorkspaceID, period, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Overview failed", "error", err)
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, overview)
+}
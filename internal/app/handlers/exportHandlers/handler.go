@@ -0,0 +1,83 @@
+package exportHandlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/export"
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+type Handler struct {
+	urlRepository   *repository.UrlRepository
+	clickRepository *repository.ClickRepository
+}
+
+func NewHandler(urlRepository *repository.UrlRepository, clickRepository *repository.ClickRepository) *Handler {
+	return &Handler{
+		urlRepository:   urlRepository,
+		clickRepository: clickRepository,
+	}
+}
+
+func (h *Handler) Links(w http.ResponseWriter, r *http.Request) {
+	format, filter, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	startStream(w, "links", format)
+	if err := export.Links(r.Context(), h.urlRepository, filter, format, w); err != nil {
+		// Headers are already sent, so the only thing left is to log and
+		// cut the stream short.
+		slog.ErrorContext(r.Context(), "Links export failed", "error", err)
+	}
+}
+
+func (h *Handler) Clicks(w http.ResponseWriter, r *http.Request) {
+	format, filter, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	startStream(w, "clicks", format)
+	if err := export.Clicks(r.Context(), h.clickRepository, filter, format, w); err != nil {
+		slog.ErrorContext(r.Context(), "Clicks export failed", "error", err)
+	}
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request) (string, repository.ExportFilter, bool) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if err := export.ValidateFormat(format); err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return "", repository.ExportFilter{}, false
+	}
+
+	filter, err := export.ParseFilter(query.Get("user_id"), query.Get("from"), query.Get("to"), query["tag"])
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return "", repository.ExportFilter{}, false
+	}
+
+	workspaceID := auth.FromRequest(r).WorkspaceID
+	filter.WorkspaceID = &workspaceID
+
+	return format, filter, true
+}
+
+func startStream(w http.ResponseWriter, name, format string) {
+	filename := fmt.Sprintf("shortli-%s-%s.%s", name, time.Now().UTC().Format("20060102"), format)
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+}
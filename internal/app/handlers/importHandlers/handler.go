@@ -0,0 +1,75 @@
+package importHandlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/app/importer"
+)
+
+const maxUploadSize = 32 << 20
+
+type Handler struct {
+	importer *importer.Importer
+}
+
+func NewHandler(importer *importer.Importer) *Handler {
+	return &Handler{
+		importer: importer,
+	}
+}
+
+// Import accepts a Bitly or YOURLS export either as a multipart "file" field
+// or as the raw request body. The format is taken from the "format" query
+// parameter, falling back to the file name and content type.
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	var body io.Reader = r.Body
+	filename := ""
+	contentType := r.Header.Get("Content-Type")
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "Required file")
+			return
+		}
+		defer file.Close()
+
+		body = file
+		filename = header.Filename
+		contentType = header.Header.Get("Content-Type")
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = importer.DetectFormat(filename, contentType)
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+	records, err := importer.Parse(format, body)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	principal := auth.FromRequest(r)
+
+	report, err := h.importer.Import(r.Context(), records, principal.WorkspaceID, principal.UserID(), dryRun)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Import failed")
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	response.JSON(w, status, report)
+}
@@ -0,0 +1,128 @@
+package streamHandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/app/stream"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+// heartbeatInterval keeps idle streams below the idle timeout of common
+// proxies and load balancers.
+const heartbeatInterval = 15 * time.Second
+
+type Handler struct {
+	hub           *stream.Hub
+	urlRepository *repository.UrlRepository
+}
+
+func NewHandler(hub *stream.Hub, urlRepository *repository.UrlRepository) *Handler {
+	return &Handler{
+		hub:           hub,
+		urlRepository: urlRepository,
+	}
+}
+
+// Workspace streams the clicks on every link of the caller's workspace.
+func (h *Handler) Workspace(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, stream.Filter{
+		WorkspaceID: auth.FromRequest(r).WorkspaceID,
+		IncludeBots: r.URL.Query().Get("include_bots") == "true",
+	})
+}
+
+// Link streams the clicks on a single link.
+func (h *Handler) Link(w http.ResponseWriter, r *http.Request) {
+	workspaceID := auth.FromRequest(r).WorkspaceID
+
+	url, err := h.urlRepository.FindWorkspaceUrlByCode(r.Context(), workspaceID, r.PathValue("shortCode"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusNotFound, "URL not found")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Database error")
+		}
+		return
+	}
+
+	h.serve(w, r, stream.Filter{
+		WorkspaceID: workspaceID,
+		URLID:       url.ID,
+		IncludeBots: r.URL.Query().Get("include_bots") == "true",
+	})
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, filter stream.Filter) {
+	sub := h.hub.Subscribe(filter)
+	if sub == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Server is shutting down")
+		return
+	}
+	defer sub.Cancel()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 5000\n: connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := writeDropped(w, sub); err != nil {
+				return
+			}
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case click, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := writeDropped(w, sub); err != nil {
+				return
+			}
+			data, err := json.Marshal(click)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: click\ndata: %s\n\n", click.ID, data); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeDropped tells the client how many clicks it missed by falling behind,
+// so that it can refetch totals instead of trusting its own tally.
+func writeDropped(w http.ResponseWriter, sub *stream.Subscription) error {
+	dropped := sub.Dropped()
+	if dropped == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(w, "event: dropped\ndata: {\"count\":%d}\n\n", dropped)
+	return err
+}
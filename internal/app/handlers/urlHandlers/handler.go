@@ -1,7 +1,6 @@
 package urlHandlers
 
 import (
-	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -12,24 +11,26 @@ import (
 	"strings"
 	"time"
 
+	"github.com/J0es1ick/shortli/internal/app/auth"
 	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/app/middleware"
+	"github.com/J0es1ick/shortli/internal/app/service"
 	"github.com/J0es1ick/shortli/internal/config"
-	"github.com/J0es1ick/shortli/internal/models"
-	"github.com/J0es1ick/shortli/internal/repository"
-	"github.com/J0es1ick/shortli/pkg/shortener"
-	"github.com/J0es1ick/shortli/pkg/validator"
-	"github.com/skip2/go-qrcode"
+	"github.com/J0es1ick/shortli/pkg/useragent"
 )
 
 type Handler struct {
 	cfg *config.Config
-	urlRepository *repository.UrlRepository
+	links *service.LinkService
+	classifier *useragent.Classifier
 }
 
-func NewHandler(cfg *config.Config, urlRepository *repository.UrlRepository) *Handler {
+func NewHandler(cfg *config.Config, links *service.LinkService, classifier *useragent.Classifier) *Handler {
 	return &Handler{
 		cfg: cfg,
-		urlRepository: urlRepository,
+		links: links,
+		classifier: classifier,
 	}
 }
 
@@ -57,113 +58,78 @@ func (h *Handler) Shorten(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.OriginalURL == "" {
-		response.Error(w, http.StatusBadRequest, "Required original_url")
-		return
-	}
-
-	normalizedURL, err := validator.ValidateURL(req.OriginalURL)
+	result, err := h.links.Shorten(r.Context(), auth.FromRequest(r), service.ShortenInput{
+		OriginalURL:   req.OriginalURL,
+		Tags:          req.Tags,
+		ReuseExisting: req.ReuseExisting,
+		Preview:       req.preview(),
+	})
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+		writeServiceError(w, err, "Failed to save URL")
 		return
 	}
-	req.OriginalURL = normalizedURL
-
-	userID := 0
-
-	existingURL, err := h.urlRepository.FindUrlByOriginalUrl(req.OriginalURL)
-	if err == nil {
-		qrCode, err := qrcode.Encode(existingURL.OriginalURL, qrcode.Low, 150)
-		if err != nil {
-			response.Error(w, http.StatusInternalServerError, "Failed to generate QR code")
-			return
-		}
-		
-		qrCodeBase64 := base64.StdEncoding.EncodeToString(qrCode)
-		response.JSON(w, http.StatusOK, UrlResponse{
-			OriginalURL:  existingURL.OriginalURL,
-			ShortCode:    existingURL.ShortCode,
-			ShortURL:     fmt.Sprintf("http://%s/%s", h.cfg.ServerPort, existingURL.ShortCode),
-			QRCodeBase64: fmt.Sprintf("data:image/png;base64,%s", qrCodeBase64),
-		})
-		return
-	}
-
-	attempt := 0
-	var shortCode string
-	var existingURLByCode *models.URL
-
-	for {
-		shortCode = shortener.GenerateShortCode(req.OriginalURL, attempt)
-		
-		existingURLByCode, err = h.urlRepository.FindUrlByCode(shortCode)
-		if err != nil && strings.Contains(err.Error(), "url not found") {
-			break 
-		}
-		
-		if existingURLByCode != nil {
-			if existingURLByCode.OriginalURL == req.OriginalURL {
-				shortCode = existingURLByCode.ShortCode
-				break
-			}
-			attempt++
-			if attempt > 5 {
-				response.Error(w, http.StatusInternalServerError, "Failed to generate unique short code")
-				return
-			}
-			continue
-		}
-		
-		break
-	}
+	url := result.URL
 
-	url := &models.URL{
-		OriginalURL: req.OriginalURL,
-		ShortCode:   shortCode,
-		UserId:      userID,
-		ClickCount:  0,
-		CreatedAt:   time.Now(),
-	}
-
-	if _, err := h.urlRepository.SaveUrl(url); err != nil {
-		if strings.Contains(err.Error(), "unique constraint violation") {
-			response.Error(w, http.StatusConflict, "URL already exists")
-			return
-		}
-		response.Error(w, http.StatusInternalServerError, "Failed to save URL")
-		return
-	}
-
-	qrCode, err := qrcode.Encode(req.OriginalURL, qrcode.Low, 150)
+	qrCode, err := h.links.QRCode(r.Context(), url)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Failed to generate QR code")
 		return
 	}
 
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+
 	qrCodeBase64 := base64.StdEncoding.EncodeToString(qrCode)
-	response.JSON(w, http.StatusCreated, UrlResponse{
-		OriginalURL:  req.OriginalURL,
-		ShortCode:    shortCode,
-		ShortURL:     fmt.Sprintf("http://%s/%s", h.cfg.ServerPort, shortCode),
+	response.JSON(w, status, UrlResponse{
+		OriginalURL:  url.OriginalURL,
+		ShortCode:    url.ShortCode,
+		ShortURL:     fmt.Sprintf("http://%s/%s", h.cfg.ServerPort, url.ShortCode),
 		QRCodeBase64: fmt.Sprintf("data:image/png;base64,%s", qrCodeBase64),
+		Tags:         url.Tags,
+		Metadata:     url.Metadata,
 	})
 }
 
 func (h *Handler) Redirect(w http.ResponseWriter, r *http.Request) {
 	shortCode := strings.TrimPrefix(r.URL.Path, "/")
-	url, err := h.urlRepository.FindUrlByCode(shortCode)
+	url, err := h.links.Resolve(r.Context(), shortCode)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, service.ErrNotFound) {
+			metrics.Redirects.WithLabelValues("miss").Inc()
 			response.Error(w, http.StatusNotFound, "URL not found")
+		} else if errors.Is(err, service.ErrDisabled) {
+			metrics.Redirects.WithLabelValues("disabled").Inc()
+			response.Error(w, http.StatusGone, "URL disabled")
 		} else {
+			metrics.Redirects.WithLabelValues("error").Inc()
 			response.Error(w, http.StatusInternalServerError, "Database error")
 		}
 		return
 	}
+	metrics.Redirects.WithLabelValues("hit").Inc()
 
-	url.ClickCount++
-	
-	if err := h.urlRepository.UpdateUrlByCode(url); err != nil {
+	// Unfurlers get the link's own preview card. They are not visitors, so
+	// the request is not counted as a click.
+	if url.HasPreview() && useragent.IsUnfurler(r.UserAgent()) {
+		writePreview(w, url, fmt.Sprintf("http://%s/%s", h.cfg.ServerPort, url.ShortCode))
+		return
+	}
+
+	clientIP := middleware.ClientIP(r)
+	classification := h.classifier.Classify(r, clientIP)
+
+	err = h.links.RecordClick(r.Context(), url, service.Click{
+		Class:     classification.Class,
+		Reason:    classification.Reason,
+		IPAddress: clientIP,
+		UserAgent: r.UserAgent(),
+		Referrer:  r.Referer(),
+		Country:   h.country(r),
+		At:        time.Now(),
+	})
+	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Failed to update click count")
 		return
 	}
@@ -178,19 +144,17 @@ func (h *Handler) UrlStats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	shortCode := strings.TrimPrefix(r.URL.Path, "/api/stats/")
-	url, err := h.urlRepository.FindUrlByCode(shortCode)
+	stats, err := h.links.Stats(r.Context(), auth.FromRequest(r), shortCode)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			response.Error(w, http.StatusNotFound, "URL not found")
-		} else {
-			response.Error(w, http.StatusInternalServerError, "Database error")
-		}
+		writeServiceError(w, err, "Database error")
 		return
 	}
 
 	response.JSON(w, http.StatusOK, UrlStatsResponse{
-		URL: *url,
-		TotalClicks: url.ClickCount,
+		URL: *stats.URL,
+		TotalClicks: stats.URL.ClickCount,
+		BotClicks: stats.URL.BotClickCount,
+		UniqueVisitors: stats.UniqueVisitors,
 	})
 }
 
@@ -200,45 +164,53 @@ func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-    if page < 1 || err != nil {
-        page = 1
-    }
-
-    limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-    if limit < 1 || limit > 100 || err != nil {
-        limit = 10 
-    }
+	page, limit, offset := parsePagination(r)
 
-    offset := (page - 1) * limit
-
-	urls, err := h.urlRepository.FindAllUrl(limit, offset)
+	links, err := h.links.List(r.Context(), auth.FromRequest(r), r.URL.Query()["tag"], limit, offset)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			response.Error(w, http.StatusNotFound, "URL not found")
-		} else {
-			response.Error(w, http.StatusInternalServerError, "Database error")
-		}
+		writeServiceError(w, err, "Database error")
 		return
 	}
 
-	total, err := h.urlRepository.GetTotalUrls()
-    if err != nil {
-        response.Error(w, http.StatusInternalServerError, "Failed to get total count")
-        return
-    }
-
 	response.JSON(w, http.StatusOK, map[string]interface{}{
-        "data": urls,
+        "data": links.Links,
         "meta": map[string]interface{}{
-            "total":     total,
+            "total":     links.Total,
             "page":      page,
             "limit":     limit,
-            "totalPages": int(math.Ceil(float64(total) / float64(limit))),
+            "totalPages": int(math.Ceil(float64(links.Total) / float64(limit))),
         },
     })
 }
 
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req UrlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	url, err := h.links.Update(r.Context(), auth.FromRequest(r), r.PathValue("shortCode"), service.UpdateInput{
+		OriginalURL: req.OriginalURL,
+		Preview:     req.preview(),
+	})
+	if err != nil {
+		writeServiceError(w, err, "Failed to update URL")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, UrlResponse{
+		OriginalURL: url.OriginalURL,
+		ShortCode:   url.ShortCode,
+		ShortURL:    fmt.Sprintf("http://%s/%s", h.cfg.ServerPort, url.ShortCode),
+	})
+}
+
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodDelete {
         response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -247,18 +219,123 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
     shortCode := strings.TrimPrefix(r.URL.Path, "/urls/")
 
-    if err := h.urlRepository.DeleteUrlByCode(shortCode); err != nil {
-        if strings.Contains(err.Error(), "not found") {
-            response.Error(w, http.StatusNotFound, "URL not found")
-        } else {
-            response.Error(w, http.StatusInternalServerError, "Failed to delete URL")
-        }
+    if _, err := h.links.Delete(r.Context(), auth.FromRequest(r), shortCode); err != nil {
+        writeServiceError(w, err, "Failed to delete URL")
         return
     }
 
     response.JSON(w, http.StatusOK, map[string]string{
         "status":  "success",
-        "message": "URL deleted successfully",
+        "message": "URL moved to trash",
         "code":    shortCode,
     })
 }
+
+func (h *Handler) Trash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	page, limit, offset := parsePagination(r)
+
+	trash, err := h.links.Trash(r.Context(), auth.FromRequest(r), limit, offset)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data": trash.Links,
+		"meta": map[string]interface{}{
+			"total":      trash.Total,
+			"page":       page,
+			"limit":      limit,
+			"totalPages": int(math.Ceil(float64(trash.Total) / float64(limit))),
+		},
+	})
+}
+
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	shortCode := r.PathValue("shortCode")
+
+	if _, err := h.links.Restore(r.Context(), auth.FromRequest(r), shortCode); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			response.Error(w, http.StatusNotFound, "URL not found in trash")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to restore URL")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "URL restored successfully",
+		"code":    shortCode,
+	})
+}
+
+// country returns the visitor's country as reported by the edge proxy, if
+// one is configured.
+func (h *Handler) country(r *http.Request) string {
+	if h.cfg.CountryHeader == "" {
+		return ""
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(r.Header.Get(h.cfg.CountryHeader)))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return ""
+	}
+	return code
+}
+
+func parsePagination(r *http.Request) (page, limit, offset int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 || err != nil {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 || err != nil {
+		limit = 10
+	}
+
+	return page, limit, (page - 1) * limit
+}
+
+// RefreshMetadata queues a new fetch of the destination page metadata.
+func (h *Handler) RefreshMetadata(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.links.RefreshMetadata(r.Context(), auth.FromRequest(r), r.PathValue("shortCode")); err != nil {
+		writeServiceError(w, err, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, map[string]string{
+		"status":  "success",
+		"message": "Metadata refresh queued",
+	})
+}
+
+// writeServiceError maps a LinkService error to a response. Unexpected
+// errors are reported with fallback.
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case service.IsValidation(err):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		response.Error(w, http.StatusNotFound, "URL not found")
+	case errors.Is(err, service.ErrDisabled):
+		response.Error(w, http.StatusGone, "URL disabled")
+	case errors.Is(err, service.ErrConflict):
+		response.Error(w, http.StatusConflict, "URL already exists")
+	case errors.Is(err, service.ErrCodeExhausted):
+		response.Error(w, http.StatusInternalServerError, "Failed to generate unique short code")
+	default:
+		response.Error(w, http.StatusInternalServerError, fallback)
+	}
+}
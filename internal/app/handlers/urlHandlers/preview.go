@@ -0,0 +1,68 @@
+package urlHandlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:url" content="{{.ShortURL}}">
+{{- if .Title}}
+<meta property="og:title" content="{{.Title}}">
+<meta name="twitter:title" content="{{.Title}}">
+{{- end}}
+{{- if .Description}}
+<meta property="og:description" content="{{.Description}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta name="description" content="{{.Description}}">
+{{- end}}
+{{- if .ImageURL}}
+<meta property="og:image" content="{{.ImageURL}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
+<meta name="twitter:card" content="summary_large_image">
+{{- else}}
+<meta name="twitter:card" content="summary">
+{{- end}}
+<meta http-equiv="refresh" content="0; url={{.Destination}}">
+</head>
+<body>
+<p><a href="{{.Destination}}">{{.Destination}}</a></p>
+</body>
+</html>
+`))
+
+type previewPage struct {
+	Title       string
+	Description string
+	ImageURL    string
+	ShortURL    string
+	Destination string
+}
+
+// writePreview renders the link's social preview for link unfurlers. The
+// refresh tag forwards any human who is served the page by mistake.
+func writePreview(w http.ResponseWriter, url *models.URL, shortURL string) {
+	page := previewPage{ShortURL: shortURL, Destination: url.OriginalURL}
+	if url.OGTitle != nil {
+		page.Title = *url.OGTitle
+	}
+	if url.OGDescription != nil {
+		page.Description = *url.OGDescription
+	}
+	if url.OGImageURL != nil {
+		page.ImageURL = *url.OGImageURL
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Vary", "User-Agent")
+	w.WriteHeader(http.StatusOK)
+	previewTemplate.Execute(w, page)
+}
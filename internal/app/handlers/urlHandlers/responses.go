@@ -1,9 +1,31 @@
 package urlHandlers
 
-import "github.com/J0es1ick/shortli/internal/models"
+import (
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/service"
+	"github.com/J0es1ick/shortli/internal/models"
+)
 
 type UrlRequest struct {
-	OriginalURL string `json:"original_url"`
+	OriginalURL string   `json:"original_url"`
+	Tags        []string `json:"tags,omitempty"`
+	// ReuseExisting returns the caller's existing link for the same URL
+	// instead of creating a new one. Defaults to true.
+	ReuseExisting *bool `json:"reuse_existing,omitempty"`
+	// Social preview served to link unfurlers. On update a missing field is
+	// left unchanged and an empty string clears it.
+	OGTitle       *string `json:"og_title,omitempty"`
+	OGDescription *string `json:"og_description,omitempty"`
+	OGImageURL    *string `json:"og_image_url,omitempty"`
+}
+
+func (req *UrlRequest) preview() service.Preview {
+	return service.Preview{
+		Title:       req.OGTitle,
+		Description: req.OGDescription,
+		ImageURL:    req.OGImageURL,
+	}
 }
 
 type UrlResponse struct {
@@ -11,9 +33,30 @@ type UrlResponse struct {
 	ShortCode    string `json:"short_code"`
 	ShortURL     string `json:"short_url"`
 	QRCodeBase64 string `json:"qr_code_base64,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Metadata     *models.LinkMetadata `json:"metadata,omitempty"`
 }
 
 type UrlStatsResponse struct {
 	models.URL
+	// TotalClicks counts human clicks only; requests from bots, link
+	// scanners and prefetchers are counted in BotClicks.
 	TotalClicks int `json:"total_clicks"`
+	BotClicks   int `json:"bot_clicks"`
+	// UniqueVisitors is an approximate count of distinct human visitors,
+	// counting a visitor once per UTC day.
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+type TimeSeriesResponse struct {
+	ShortCode string               `json:"short_code"`
+	Interval  string               `json:"interval"`
+	TZ        string               `json:"tz"`
+	From      time.Time            `json:"from"`
+	To        time.Time            `json:"to"`
+	Data      []models.ClickBucket `json:"data"`
+	Totals    struct {
+		Clicks    int64 `json:"clicks"`
+		BotClicks int64 `json:"bot_clicks"`
+	} `json:"totals"`
 }
\ No newline at end of file
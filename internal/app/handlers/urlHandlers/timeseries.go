@@ -0,0 +1,78 @@
+package urlHandlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/app/service"
+)
+
+// TimeSeries returns the clicks of a link per interval between from and to.
+// Bounds are RFC 3339 timestamps or dates in tz; a date as to includes the
+// whole day. Both are widened to whole buckets.
+func (h *Handler) TimeSeries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	tz := query.Get("tz")
+	if tz == "" {
+		tz = "UTC"
+	}
+	// LoadLocation accepts "Local" for the server's own zone, which
+	// callers cannot know.
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		response.Error(w, http.StatusBadRequest, "Invalid tz")
+		return
+	}
+
+	in := service.SeriesInput{Interval: query.Get("interval"), Location: loc}
+	if value := query.Get("to"); value != "" {
+		if in.To, err = parseSeriesTime(value, loc, true); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid to: "+err.Error())
+			return
+		}
+	}
+	if value := query.Get("from"); value != "" {
+		if in.From, err = parseSeriesTime(value, loc, false); err != nil {
+			response.Error(w, http.StatusBadRequest, "Invalid from: "+err.Error())
+			return
+		}
+	}
+
+	series, err := h.links.Series(r.Context(), auth.FromRequest(r), r.PathValue("shortCode"), in)
+	if err != nil {
+		writeServiceError(w, err, "Database error")
+		return
+	}
+
+	resp := TimeSeriesResponse{
+		ShortCode: series.URL.ShortCode,
+		Interval:  series.Interval,
+		TZ:        series.Location.String(),
+		From:      series.From,
+		To:        series.To,
+		Data:      series.Buckets,
+	}
+	resp.Totals.Clicks = series.Clicks
+	resp.Totals.BotClicks = series.BotClicks
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func parseSeriesTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
@@ -0,0 +1,195 @@
+package webhookHandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/app/webhooks"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+	"github.com/J0es1ick/shortli/pkg/validator"
+)
+
+type Handler struct {
+	webhookRepository *repository.WebhookRepository
+	validator         *validator.Validator
+}
+
+func NewHandler(webhookRepository *repository.WebhookRepository, validator *validator.Validator) *Handler {
+	return &Handler{
+		webhookRepository: webhookRepository,
+		validator:         validator,
+	}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.URL == "" {
+		response.Error(w, http.StatusBadRequest, "Required url")
+		return
+	}
+
+	targetURL, err := h.validator.Validate(req.URL)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events := req.Events
+	if len(events) == 0 {
+		events = models.WebhookEvents
+	}
+	for _, event := range events {
+		if !slices.Contains(models.WebhookEvents, event) {
+			response.Error(w, http.StatusBadRequest, fmt.Sprintf("Unknown event %q", event))
+			return
+		}
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to generate secret")
+		return
+	}
+
+	principal := auth.FromRequest(r)
+
+	webhook := &models.Webhook{
+		UserId:      principal.UserID(),
+		WorkspaceID: principal.WorkspaceID,
+		TargetURL:   targetURL,
+		Secret:      secret,
+		Events:      events,
+		Active:      true,
+		CreatedAt:   time.Now(),
+	}
+
+	id, err := h.webhookRepository.SaveWebhook(webhook)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to save webhook")
+		return
+	}
+	webhook.ID = id
+
+	response.JSON(w, http.StatusCreated, WebhookResponse{
+		Webhook: *webhook,
+		Secret:  secret,
+	})
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.webhookRepository.FindWebhooksByWorkspace(auth.FromRequest(r).WorkspaceID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data": list,
+	})
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+
+	if err := h.webhookRepository.DeleteWebhook(id, auth.FromRequest(r).WorkspaceID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusNotFound, "Webhook not found")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to delete webhook")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "Webhook deleted successfully",
+	})
+}
+
+func (h *Handler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 || err != nil {
+		limit = 20
+	}
+
+	deliveries, err := h.webhookRepository.FindDeliveries(webhook.ID, status, limit)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data": deliveries,
+	})
+}
+
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(r.PathValue("deliveryId"), 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid delivery id")
+		return
+	}
+
+	if err := h.webhookRepository.RequeueDelivery(webhook.ID, deliveryID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusNotFound, "Dead delivery not found")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to requeue delivery")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, map[string]string{
+		"status":  "success",
+		"message": "Delivery requeued",
+	})
+}
+
+func (h *Handler) findWebhook(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid webhook id")
+		return nil, false
+	}
+
+	webhook, err := h.webhookRepository.FindWebhookByID(id, auth.FromRequest(r).WorkspaceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusNotFound, "Webhook not found")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Database error")
+		}
+		return nil, false
+	}
+
+	return webhook, true
+}
@@ -0,0 +1,13 @@
+package webhookHandlers
+
+import "github.com/J0es1ick/shortli/internal/models"
+
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type WebhookResponse struct {
+	models.Webhook
+	Secret string `json:"secret,omitempty"`
+}
@@ -0,0 +1,240 @@
+package workspaceHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type Handler struct {
+	workspaceRepository *repository.WorkspaceRepository
+}
+
+func NewHandler(workspaceRepository *repository.WorkspaceRepository) *Handler {
+	return &Handler{
+		workspaceRepository: workspaceRepository,
+	}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := h.workspaceRepository.FindWorkspacesByUser(auth.FromRequest(r).UserID())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data": workspaces,
+	})
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req WorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		response.Error(w, http.StatusBadRequest, "Required name")
+		return
+	}
+
+	workspace, err := h.workspaceRepository.CreateWorkspace(req.Name, auth.FromRequest(r).UserID())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to create workspace")
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, workspace)
+}
+
+func (h *Handler) Members(w http.ResponseWriter, r *http.Request) {
+	members, err := h.workspaceRepository.FindMembers(auth.FromRequest(r).WorkspaceID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data": members,
+	})
+}
+
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	var req RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if !models.ValidRole(req.Role) {
+		response.Error(w, http.StatusBadRequest, "Role must be owner, editor or viewer")
+		return
+	}
+
+	if err := h.workspaceRepository.UpdateMemberRole(auth.FromRequest(r).WorkspaceID, userID, req.Role); err != nil {
+		writeMembershipError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "Member role updated",
+	})
+}
+
+// RemoveMember lets owners remove anyone and every member remove themselves.
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	principal := auth.FromRequest(r)
+	if userID != principal.UserID() && !principal.Allows(models.RoleOwner) {
+		response.Error(w, http.StatusForbidden, "Insufficient permissions")
+		return
+	}
+
+	if err := h.workspaceRepository.RemoveMember(principal.WorkspaceID, userID); err != nil {
+		writeMembershipError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "Member removed",
+	})
+}
+
+func (h *Handler) Invite(w http.ResponseWriter, r *http.Request) {
+	var req InvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if !strings.Contains(req.Email, "@") {
+		response.Error(w, http.StatusBadRequest, "Required email")
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.RoleViewer
+	}
+	if !models.ValidRole(req.Role) {
+		response.Error(w, http.StatusBadRequest, "Role must be owner, editor or viewer")
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateToken("inv_")
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to generate invitation")
+		return
+	}
+
+	principal := auth.FromRequest(r)
+	now := time.Now()
+
+	invitation := &models.Invitation{
+		WorkspaceID: principal.WorkspaceID,
+		Email:       req.Email,
+		Role:        req.Role,
+		TokenHash:   tokenHash,
+		InvitedBy:   principal.UserID(),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(invitationTTL),
+	}
+
+	id, err := h.workspaceRepository.SaveInvitation(invitation)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to save invitation")
+		return
+	}
+	invitation.ID = id
+
+	response.JSON(w, http.StatusCreated, InvitationResponse{
+		Invitation: *invitation,
+		Token:      token,
+	})
+}
+
+func (h *Handler) Invitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.workspaceRepository.FindPendingInvitations(auth.FromRequest(r).WorkspaceID)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"data": invitations,
+	})
+}
+
+func (h *Handler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := strconv.Atoi(r.PathValue("invitationId"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid invitation id")
+		return
+	}
+
+	if err := h.workspaceRepository.DeleteInvitation(auth.FromRequest(r).WorkspaceID, invitationID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusNotFound, "Invitation not found")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to revoke invitation")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "Invitation revoked",
+	})
+}
+
+func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	principal := auth.FromRequest(r)
+
+	membership, err := h.workspaceRepository.AcceptInvitation(auth.HashToken(r.PathValue("token")), principal.User)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusNotFound, "Invitation not found or expired")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Failed to accept invitation")
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, membership)
+}
+
+func writeMembershipError(w http.ResponseWriter, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		response.Error(w, http.StatusNotFound, "Member not found")
+	case strings.Contains(err.Error(), "at least one owner"):
+		response.Error(w, http.StatusConflict, "Workspace must keep at least one owner")
+	default:
+		response.Error(w, http.StatusInternalServerError, "Failed to update membership")
+	}
+}
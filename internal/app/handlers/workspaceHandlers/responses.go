@@ -0,0 +1,21 @@
+package workspaceHandlers
+
+import "github.com/J0es1ick/shortli/internal/models"
+
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type InvitationResponse struct {
+	models.Invitation
+	Token string `json:"token"`
+}
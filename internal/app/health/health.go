@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+)
+
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency is usable. It must honour ctx.
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	// NonFatal marks checks whose failure is reported without failing
+	// the probe.
+	NonFatal bool `json:"non_fatal,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name     string
+	check    Check
+	nonFatal bool
+}
+
+// Checker serves the liveness and readiness probes. Readiness runs every
+// registered check concurrently and fails as soon as draining starts, so that
+// load balancers stop routing traffic before the server shuts down.
+type Checker struct {
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// AddNonFatal adds a check that readiness reports without failing on, such
+// as the status of a background task: every replica shares the task's
+// database, so taking them out of rotation would not help. A failing
+// non-fatal check turns the report "degraded".
+func (c *Checker) AddNonFatal(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check, nonFatal: true})
+}
+
+// Drain makes every following readiness probe fail.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Liveness only reports that the process is alive; it runs no checks.
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, Report{Status: "ok"})
+}
+
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		response.JSON(w, http.StatusServiceUnavailable, Report{Status: "draining"})
+		return
+	}
+
+	report := c.Run(r.Context())
+
+	status := http.StatusOK
+	if report.Status == "failing" {
+		status = http.StatusServiceUnavailable
+	}
+
+	response.JSON(w, status, report)
+}
+
+// Run executes all checks and aggregates their results: "failing" if a
+// fatal check failed, "degraded" if only non-fatal ones did.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := nc.check(ctx)
+
+			result := CheckResult{Status: "ok", DurationMs: time.Since(start).Milliseconds(), NonFatal: nc.nonFatal}
+			if err != nil {
+				result.Status = "failing"
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: "ok", Checks: make(map[string]CheckResult, len(checks))}
+	for i, nc := range checks {
+		report.Checks[nc.name] = results[i]
+		switch {
+		case results[i].Status == "ok":
+		case !nc.nonFatal:
+			report.Status = "failing"
+		case report.Status == "ok":
+			report.Status = "degraded"
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func probe(t *testing.T, handler http.HandlerFunc) (int, Report) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var report Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, report
+}
+
+func TestReadiness(t *testing.T) {
+	failing := func(context.Context) error { return errors.New("down") }
+	passing := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name       string
+		database   Check
+		cleanup    Check
+		wantCode   int
+		wantStatus string
+	}{
+		{"all passing", passing, passing, http.StatusOK, "ok"},
+		{"non-fatal failing", passing, failing, http.StatusOK, "degraded"},
+		{"fatal failing", failing, passing, http.StatusServiceUnavailable, "failing"},
+		{"both failing", failing, failing, http.StatusServiceUnavailable, "failing"},
+	}
+
+	for _, tt := range tests {
+		checker := NewChecker()
+		checker.Add("database", tt.database)
+		checker.AddNonFatal("cleanup", tt.cleanup)
+
+		code, report := probe(t, checker.Readiness)
+		if code != tt.wantCode || report.Status != tt.wantStatus {
+			t.Errorf("%s: readiness = %d %s, want %d %s", tt.name, code, report.Status, tt.wantCode, tt.wantStatus)
+		}
+		cleanup, ok := report.Checks["cleanup"]
+		if !ok || !cleanup.NonFatal {
+			t.Errorf("%s: readiness reports cleanup as %+v, want a non-fatal entry", tt.name, cleanup)
+		}
+	}
+}
+
+func TestLivenessRunsNoChecks(t *testing.T) {
+	checker := NewChecker()
+	checker.Add("database", func(context.Context) error {
+		t.Error("liveness ran a check")
+		return nil
+	})
+
+	code, report := probe(t, checker.Liveness)
+	if code != http.StatusOK || report.Status != "ok" || len(report.Checks) != 0 {
+		t.Errorf("liveness = %d %+v, want 200 ok without checks", code, report)
+	}
+
+	checker.Drain()
+	if code, _ := probe(t, checker.Readiness); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness while draining = %d, want 503", code)
+	}
+}
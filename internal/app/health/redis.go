@@ -0,0 +1,70 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// RedisCheck pings the Redis server at rawURL (redis://[:password@]host:port)
+// using the bare RESP protocol, so no client library is needed to probe it.
+func RedisCheck(rawURL string) Check {
+	return func(ctx context.Context) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("invalid redis url: %v", err)
+		}
+
+		addr := u.Host
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), "6379")
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return fmt.Errorf("redis unreachable: %v", err)
+		}
+		defer conn.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+
+		reader := bufio.NewReader(conn)
+
+		if password, ok := u.User.Password(); ok {
+			if err := redisCommand(conn, reader, "+OK", "AUTH", password); err != nil {
+				return err
+			}
+		}
+
+		return redisCommand(conn, reader, "+PONG", "PING")
+	}
+}
+
+func redisCommand(conn net.Conn, reader *bufio.Reader, want string, args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		return fmt.Errorf("redis write error: %v", err)
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("redis read error: %v", err)
+	}
+
+	if reply := strings.TrimSpace(line); reply != want {
+		return fmt.Errorf("unexpected redis reply %q to %s", reply, args[0])
+	}
+
+	return nil
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 )
 
+// RequestIDHeader is set on every response by the request ID middleware.
+const RequestIDHeader = "X-Request-ID"
+
 func JSON(w http.ResponseWriter, statusCode int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -12,5 +15,9 @@ func JSON(w http.ResponseWriter, statusCode int, payload interface{}) {
 }
 
 func Error(w http.ResponseWriter, statusCode int, message string) {
-	JSON(w, statusCode, map[string]string{"error": message})
-}
\ No newline at end of file
+	body := map[string]string{"error": message}
+	if id := w.Header().Get(RequestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	JSON(w, statusCode, body)
+}
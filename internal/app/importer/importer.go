@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/service"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+	"github.com/J0es1ick/shortli/pkg/validator"
+)
+
+var shortCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+type Conflict struct {
+	Line        int    `json:"line"`
+	ShortCode   string `json:"short_code"`
+	OriginalURL string `json:"original_url"`
+	ExistingURL string `json:"existing_url,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+type RowError struct {
+	Line      int    `json:"line"`
+	ShortCode string `json:"short_code,omitempty"`
+	Error     string `json:"error"`
+}
+
+type Report struct {
+	DryRun    bool       `json:"dry_run"`
+	Total     int        `json:"total"`
+	Imported  int        `json:"imported"`
+	Skipped   int        `json:"skipped"`
+	Conflicts []Conflict `json:"conflicts"`
+	Errors    []RowError `json:"errors"`
+}
+
+type Importer struct {
+	urlRepository *repository.UrlRepository
+	links         *service.LinkService
+	validator     *validator.Validator
+}
+
+func NewImporter(urlRepository *repository.UrlRepository, links *service.LinkService, validator *validator.Validator) *Importer {
+	return &Importer{
+		urlRepository: urlRepository,
+		links:         links,
+		validator:     validator,
+	}
+}
+
+// Import stores records in workspaceID on behalf of userID, keeping their
+// original short codes, creation dates and click totals. Records whose code is
+// already taken are reported as conflicts and never overwrite existing links.
+// With dryRun set nothing is written but the report is computed the same way.
+//
+// Links are stored through LinkService.Import, so each imported link
+// publishes link.created and has its metadata fetched like a shortened one.
+// Hosts are screened once per import, and a cancelled ctx stops the import
+// between records.
+func (i *Importer) Import(ctx context.Context, records []Record, workspaceID, userID int, dryRun bool) (*Report, error) {
+	report := &Report{
+		DryRun:    dryRun,
+		Total:     len(records),
+		Conflicts: []Conflict{},
+		Errors:    []RowError{},
+	}
+
+	seen := make(map[string]int, len(records))
+	now := time.Now()
+	batch := i.validator.ForBatch()
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if record.ShortCode == "" || !shortCodePattern.MatchString(record.ShortCode) {
+			report.addError(record, "missing or invalid short code")
+			continue
+		}
+
+		originalURL, err := batch.Validate(record.OriginalURL)
+		if err != nil {
+			report.addError(record, err.Error())
+			continue
+		}
+
+		canonicalHash, err := batch.CanonicalHash(originalURL)
+		if err != nil {
+			report.addError(record, err.Error())
+			continue
+		}
+
+		if line, ok := seen[record.ShortCode]; ok {
+			report.addConflict(record, originalURL, "", fmt.Sprintf("duplicate of line %d in this file", line))
+			continue
+		}
+		seen[record.ShortCode] = record.Line
+
+		reserved, err := i.urlRepository.IsCodeReserved(ctx, record.ShortCode)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			i.reportExisting(ctx, report, record, workspaceID, originalURL)
+			continue
+		}
+
+		if dryRun {
+			report.Imported++
+			continue
+		}
+
+		createdAt := now
+		if record.CreatedAt != nil {
+			createdAt = *record.CreatedAt
+		}
+
+		url := &models.URL{
+			OriginalURL:   originalURL,
+			ShortCode:     record.ShortCode,
+			UserId:        userID,
+			WorkspaceID:   workspaceID,
+			ClickCount:    record.ClickCount,
+			CreatedAt:     createdAt,
+			Tags:          record.Tags,
+			ImportedAt:    &now,
+			CanonicalHash: canonicalHash,
+		}
+
+		if err := i.links.Import(ctx, url); err != nil {
+			if errors.Is(err, service.ErrConflict) {
+				report.addConflict(record, originalURL, "", "short code was taken during import")
+				continue
+			}
+			report.addError(record, err.Error())
+			continue
+		}
+
+		report.Imported++
+	}
+
+	return report, nil
+}
+
+// reportExisting classifies a reserved code: an identical active link in the
+// same workspace is a harmless re-import, anything else is a conflict. Links
+// of other workspaces are never disclosed.
+func (i *Importer) reportExisting(ctx context.Context, report *Report, record Record, workspaceID int, originalURL string) {
+	existing, err := i.urlRepository.FindWorkspaceUrlByCode(ctx, workspaceID, record.ShortCode)
+	if err != nil {
+		report.addConflict(record, originalURL, "", "short code already in use")
+		return
+	}
+
+	if existing.OriginalURL == originalURL {
+		report.Skipped++
+		return
+	}
+
+	report.addConflict(record, originalURL, existing.OriginalURL, "short code already in use")
+}
+
+func (r *Report) addConflict(record Record, originalURL, existingURL, reason string) {
+	r.Conflicts = append(r.Conflicts, Conflict{
+		Line:        record.Line,
+		ShortCode:   record.ShortCode,
+		OriginalURL: originalURL,
+		ExistingURL: existingURL,
+		Reason:      reason,
+	})
+}
+
+func (r *Report) addError(record Record, message string) {
+	r.Errors = append(r.Errors, RowError{
+		Line:      record.Line,
+		ShortCode: record.ShortCode,
+		Error:     message,
+	})
+}
@@ -0,0 +1,280 @@
+package importer
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Record is one link read from a foreign export. Line is the 1-based record
+// position in the file and is only used for reporting.
+type Record struct {
+	Line        int
+	ShortCode   string
+	OriginalURL string
+	CreatedAt   *time.Time
+	ClickCount  int
+	Tags        []string
+}
+
+// Column names used by Bitly and YOURLS exports, normalized to lower case
+// with underscores. The first alias present in a record wins.
+var (
+	urlAliases     = []string{"long_url", "url", "original_url", "destination"}
+	codeAliases    = []string{"keyword", "short_code", "code"}
+	linkAliases    = []string{"link", "bitlink", "shorturl", "short_url", "short_link", "id"}
+	createdAliases = []string{"created_at", "created", "timestamp", "date"}
+	clicksAliases  = []string{"clicks", "total_clicks", "click_count"}
+	tagsAliases    = []string{"tags", "tag"}
+	dateLayouts    = []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.DateOnly}
+)
+
+func DetectFormat(filename, contentType string) string {
+	if strings.HasSuffix(strings.ToLower(filename), ".json") || strings.Contains(contentType, "json") {
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+// Parse reads a Bitly or YOURLS export. Both CSV exports with a header row and
+// the JSON shapes returned by their APIs ({"links": [...]} or
+// {"links": {"link_1": {...}}}) are understood.
+func Parse(format string, r io.Reader) ([]Record, error) {
+	var rows []map[string]string
+	var err error
+
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(r)
+	case FormatJSON:
+		rows, err = readJSON(r)
+	default:
+		return nil, fmt.Errorf("format must be %s or %s", FormatCSV, FormatJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(rows))
+	for i, row := range rows {
+		records = append(records, toRecord(i+1, row))
+	}
+
+	return records, nil
+}
+
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	for i := range header {
+		header[i] = normalizeKey(header[i])
+	}
+
+	rows := []map[string]string{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+
+		row := make(map[string]string, len(header))
+		for i, value := range fields {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func readJSON(r io.Reader) ([]map[string]string, error) {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %v", err)
+	}
+
+	if obj, ok := doc.(map[string]interface{}); ok {
+		if links, ok := obj["links"]; ok {
+			doc = links
+		}
+	}
+
+	var items []interface{}
+	switch v := doc.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		// YOURLS keys its links as "link_1", "link_2", ...
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sortLinkKeys(keys)
+		for _, key := range keys {
+			items = append(items, v[key])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JSON export layout")
+	}
+
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unsupported JSON export layout")
+		}
+
+		row := make(map[string]string, len(obj))
+		for key, value := range obj {
+			row[normalizeKey(key)] = stringify(value)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func toRecord(line int, row map[string]string) Record {
+	record := Record{
+		Line:        line,
+		OriginalURL: first(row, urlAliases),
+		ShortCode:   first(row, codeAliases),
+	}
+
+	if record.ShortCode == "" {
+		record.ShortCode = codeFromLink(first(row, linkAliases))
+	} else {
+		record.ShortCode = codeFromLink(record.ShortCode)
+	}
+
+	if created := first(row, createdAliases); created != "" {
+		if t, ok := parseDate(created); ok {
+			record.CreatedAt = &t
+		}
+	}
+
+	if clicks, err := strconv.Atoi(first(row, clicksAliases)); err == nil && clicks > 0 {
+		record.ClickCount = clicks
+	}
+
+	for _, tag := range strings.FieldsFunc(first(row, tagsAliases), func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	}) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			record.Tags = append(record.Tags, tag)
+		}
+	}
+
+	return record
+}
+
+// codeFromLink turns "https://bit.ly/abc", "bit.ly/abc" or "abc" into "abc".
+func codeFromLink(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" || !strings.Contains(link, "/") {
+		return link
+	}
+
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return strings.Trim(parsed.Path, "/")
+}
+
+func first(row map[string]string, aliases []string) string {
+	for _, alias := range aliases {
+		if value := row[alias]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	key = strings.TrimPrefix(key, "\ufeff")
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s := stringify(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ",")
+	default:
+		return ""
+	}
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), true
+	}
+	return time.Time{}, false
+}
+
+// sortLinkKeys orders "link_2" before "link_10" so that reports follow the
+// order of the original export.
+func sortLinkKeys(keys []string) {
+	index := func(key string) int {
+		n, err := strconv.Atoi(key[strings.LastIndex(key, "_")+1:])
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(index(a), index(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+}
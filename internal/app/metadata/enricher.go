@@ -0,0 +1,123 @@
+package metadata
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+const (
+	queueSize    = 256
+	workers      = 4
+	sweepBatch   = 50
+	sweepTimeout = 30 * time.Second
+)
+
+type job struct {
+	urlID       int
+	originalURL string
+}
+
+// Enricher fetches link metadata in the background. Requests are recorded
+// as pending in the database first, so a fetch dropped because the queue is
+// full or the process restarted is picked up by the periodic sweep.
+type Enricher struct {
+	metadataRepository *repository.MetadataRepository
+	fetcher            *Fetcher
+	interval           time.Duration
+	queue              chan job
+}
+
+func NewEnricher(metadataRepository *repository.MetadataRepository, fetcher *Fetcher, interval time.Duration) *Enricher {
+	return &Enricher{
+		metadataRepository: metadataRepository,
+		fetcher:            fetcher,
+		interval:           interval,
+		queue:              make(chan job, queueSize),
+	}
+}
+
+// Request marks url as pending and queues a fetch. Failures are logged
+// rather than returned so that enrichment never fails the request that
+// created the link.
+func (e *Enricher) Request(ctx context.Context, url *models.URL) {
+	if err := e.metadataRepository.RequestFetch(ctx, url.ID); err != nil {
+		slog.ErrorContext(ctx, "Metadata request failed", "short_code", url.ShortCode, "error", err)
+		return
+	}
+
+	select {
+	case e.queue <- job{urlID: url.ID, originalURL: url.OriginalURL}:
+	default:
+		slog.WarnContext(ctx, "Metadata queue full, deferring to sweep", "short_code", url.ShortCode)
+	}
+}
+
+func (e *Enricher) Start() {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range e.queue {
+				e.enrich(j)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.sweep()
+	}
+}
+
+func (e *Enricher) sweep() {
+	ctx := context.Background()
+
+	urls, err := e.metadataRepository.FindPendingURLs(ctx, sweepTimeout.Seconds(), sweepBatch)
+	if err != nil {
+		slog.Error("Metadata sweep failed", "error", err)
+		return
+	}
+
+	for _, url := range urls {
+		select {
+		case e.queue <- job{urlID: url.ID, originalURL: url.OriginalURL}:
+		default:
+			return
+		}
+	}
+}
+
+func (e *Enricher) enrich(j job) {
+	ctx := context.Background()
+	metadata := &models.LinkMetadata{URLID: j.urlID, Status: models.MetadataStatusOK}
+
+	page, err := e.fetcher.Fetch(ctx, j.originalURL)
+	if err != nil {
+		message := err.Error()
+		metadata.Status = models.MetadataStatusFailed
+		metadata.LastError = &message
+	} else {
+		metadata.Title = optional(page.Title)
+		metadata.Description = optional(page.Description)
+		metadata.SiteName = optional(page.SiteName)
+		metadata.ImageURL = optional(page.ImageURL)
+		metadata.OGType = optional(page.OGType)
+		metadata.TwitterCard = optional(page.TwitterCard)
+		metadata.FaviconURL = optional(page.FaviconURL)
+	}
+
+	if err := e.metadataRepository.SaveMetadata(ctx, metadata); err != nil {
+		slog.Error("Metadata save failed", "url_id", j.urlID, "error", err)
+	}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,212 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	fetchTimeout  = 5 * time.Second
+	maxBodySize   = 1 << 20
+	maxRedirects  = 5
+	maxFieldRunes = 512
+)
+
+// Page is the metadata extracted from an HTML document.
+type Page struct {
+	Title       string
+	Description string
+	SiteName    string
+	ImageURL    string
+	OGType      string
+	TwitterCard string
+	FaviconURL  string
+}
+
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher wraps client, which should refuse connections to internal
+// addresses. Its redirect policy is replaced by a stricter one.
+func NewFetcher(client *http.Client) *Fetcher {
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("too many redirects")
+		}
+		return nil
+	}
+	return &Fetcher{client: &c}
+}
+
+// Fetch downloads at most maxBodySize bytes of the page at rawURL and
+// extracts its metadata. Only HTML responses are parsed.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %v", err)
+	}
+	req.Header.Set("User-Agent", "Shortli-Metadata/1.0 (+link preview)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxBodySize), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("decode body: %v", err)
+	}
+
+	page, err := Parse(body, resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// Parse extracts metadata from the <head> of an HTML document. Relative image
+// and icon URLs are resolved against base; without an icon link the favicon
+// defaults to /favicon.ico.
+func Parse(r io.Reader, base *url.URL) (*Page, error) {
+	page := &Page{}
+	meta := map[string]string{}
+	var icon string
+
+	z := html.NewTokenizer(r)
+	inTitle := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != nil && err != io.EOF {
+				return nil, fmt.Errorf("parse html: %v", err)
+			}
+			return page.finish(meta, icon, base), nil
+
+		case html.TextToken:
+			if inTitle && page.Title == "" {
+				page.Title = string(z.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return page.finish(meta, icon, base), nil
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := map[string]string{}
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+
+			switch string(name) {
+			case "title":
+				inTitle = true
+			case "meta":
+				key := strings.ToLower(attrs["property"])
+				if key == "" {
+					key = strings.ToLower(attrs["name"])
+				}
+				if key != "" && attrs["content"] != "" {
+					if _, seen := meta[key]; !seen {
+						meta[key] = attrs["content"]
+					}
+				}
+			case "link":
+				rel := strings.Fields(strings.ToLower(attrs["rel"]))
+				for _, r := range rel {
+					if (r == "icon" || r == "apple-touch-icon") && icon == "" && attrs["href"] != "" {
+						icon = attrs["href"]
+					}
+				}
+			case "body":
+				return page.finish(meta, icon, base), nil
+			}
+		}
+	}
+}
+
+func (p *Page) finish(meta map[string]string, icon string, base *url.URL) *Page {
+	p.Title = clean(firstOf(meta["og:title"], meta["twitter:title"], p.Title))
+	p.Description = clean(firstOf(meta["og:description"], meta["twitter:description"], meta["description"]))
+	p.SiteName = clean(meta["og:site_name"])
+	p.OGType = clean(meta["og:type"])
+	p.TwitterCard = clean(meta["twitter:card"])
+	p.ImageURL = resolve(base, firstOf(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"]))
+
+	if icon == "" {
+		icon = "/favicon.ico"
+	}
+	p.FaviconURL = resolve(base, icon)
+
+	return p
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func clean(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > maxFieldRunes {
+		s = string([]rune(s)[:maxFieldRunes])
+	}
+	return s
+}
+
+// resolve makes ref absolute and drops anything that is not http(s), such as
+// data: or javascript: URLs.
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+
+	return u.String()
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/internal/repository"
+)
+
+const WorkspaceHeader = "X-Workspace-ID"
+
+type Auth struct {
+	userRepository      *repository.UserRepository
+	workspaceRepository *repository.WorkspaceRepository
+}
+
+func NewAuth(userRepository *repository.UserRepository, workspaceRepository *repository.WorkspaceRepository) *Auth {
+	return &Auth{
+		userRepository:      userRepository,
+		workspaceRepository: workspaceRepository,
+	}
+}
+
+// Authenticated only requires a valid API key. It is used for endpoints that
+// are not tied to a single workspace.
+func (a *Auth) Authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := a.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{User: user})))
+	}
+}
+
+// Require authenticates the caller, resolves the workspace the request acts
+// on and checks that the caller holds at least role in it. The workspace is
+// taken from the {workspaceId} path value, then the X-Workspace-ID header,
+// and finally defaults to the caller's oldest membership.
+func (a *Auth) Require(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := a.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		rawID := r.PathValue("workspaceId")
+		if rawID == "" {
+			rawID = r.Header.Get(WorkspaceHeader)
+		}
+
+		var membership *models.Membership
+		var err error
+		if rawID != "" {
+			workspaceID, convErr := strconv.Atoi(rawID)
+			if convErr != nil {
+				response.Error(w, http.StatusBadRequest, "Invalid workspace id")
+				return
+			}
+			membership, err = a.workspaceRepository.FindMembership(workspaceID, user.ID)
+		} else {
+			membership, err = a.workspaceRepository.FindDefaultMembership(user.ID)
+		}
+
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				response.Error(w, http.StatusNotFound, "Workspace not found")
+			} else {
+				response.Error(w, http.StatusInternalServerError, "Database error")
+			}
+			return
+		}
+
+		principal := &auth.Principal{
+			User:        user,
+			WorkspaceID: membership.WorkspaceID,
+			Role:        membership.Role,
+		}
+
+		if !principal.Allows(role) {
+			response.Error(w, http.StatusForbidden, "Insufficient permissions")
+			return
+		}
+
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+func (a *Auth) authenticate(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	key := r.Header.Get("X-API-Key")
+	if header := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(header, "Bearer ") {
+		key = strings.TrimPrefix(header, "Bearer ")
+	}
+
+	if key == "" {
+		response.Error(w, http.StatusUnauthorized, "Authentication required")
+		return nil, false
+	}
+
+	user, err := a.userRepository.FindUserByAPIKey(auth.HashToken(key))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			response.Error(w, http.StatusUnauthorized, "Invalid API key")
+		} else {
+			response.Error(w, http.StatusInternalServerError, "Database error")
+		}
+		return nil, false
+	}
+
+	return user, true
+}
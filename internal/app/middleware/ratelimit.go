@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"sync"
 	"time"
 
 	response "github.com/J0es1ick/shortli/internal/app/httputils"
+	"github.com/J0es1ick/shortli/internal/app/metrics"
 )
 
 type RateLimiter struct {
@@ -25,40 +29,72 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		clientIP := getClientIP(r) 
-
-		rl.mux.Lock()
-		defer rl.mux.Unlock()
-
-		now := time.Now()
-		if _, exists := rl.requests[clientIP]; !exists {
-			rl.requests[clientIP] = []time.Time{}
-		}
-
-		validRequests := []time.Time{}
-		for _, t := range rl.requests[clientIP] {
-			if now.Sub(t) <= rl.window {
-				validRequests = append(validRequests, t)
-			}
-		}
-		rl.requests[clientIP] = validRequests
-
-		if len(rl.requests[clientIP]) >= rl.limit {
+		if !rl.allow(ClientIP(r)) {
+			metrics.RateLimited.Inc()
 			response.Error(w, http.StatusTooManyRequests, "Rate limit exceeded")
 			return
 		}
 
-		rl.requests[clientIP] = append(rl.requests[clientIP], now)
+		// The lock is released before the request is served, so long
+		// exports and live streams don't hold up other clients.
 		next.ServeHTTP(w, r)
 	})
 }
 
-func getClientIP(r *http.Request) string {
-    if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
+// allow records a request from clientIP and reports whether it is within
+// the limit.
+func (rl *RateLimiter) allow(clientIP string) bool {
+	rl.mux.Lock()
+	defer rl.mux.Unlock()
+
+	now := time.Now()
+	validRequests := []time.Time{}
+	for _, t := range rl.requests[clientIP] {
+		if now.Sub(t) <= rl.window {
+			validRequests = append(validRequests, t)
+		}
+	}
+	rl.requests[clientIP] = validRequests
+
+	if len(validRequests) >= rl.limit {
+		return false
+	}
+
+	rl.requests[clientIP] = append(validRequests, now)
+	return true
+}
+
+// ClientIP returns the address of the client without a port. The proxy in
+// front of the service is trusted to set X-Forwarded-For, whose first hop
+// is the original client, or X-Real-IP. r.RemoteAddr is used when neither
+// holds an address; it is returned as is only if it can't be parsed.
+func ClientIP(r *http.Request) string {
+    if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+        first, _, _ := strings.Cut(xff, ",")
+        if ip, ok := parseIP(first); ok {
+            return ip
+        }
+    }
+    if ip, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
         return ip
     }
-    if ip := r.Header.Get("X-Real-IP"); ip != "" {
+    if ip, ok := parseIP(r.RemoteAddr); ok {
         return ip
     }
     return r.RemoteAddr
-}
\ No newline at end of file
+}
+
+// parseIP normalizes "ip", "ip:port" and "[ipv6]:port" to the bare address,
+// with IPv4-mapped IPv6 addresses reported as IPv4.
+func parseIP(value string) (string, bool) {
+    value = strings.TrimSpace(value)
+    if host, _, err := net.SplitHostPort(value); err == nil {
+        value = host
+    }
+
+    addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+    if err != nil {
+        return "", false
+    }
+    return addr.Unmap().String(), true
+}
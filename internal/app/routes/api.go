@@ -3,22 +3,97 @@ package routes
 import (
 	"net/http"
 
+	"github.com/J0es1ick/shortli/internal/app/analytics"
+	"github.com/J0es1ick/shortli/internal/app/handlers/analyticsHandlers"
+	"github.com/J0es1ick/shortli/internal/app/handlers/exportHandlers"
+	"github.com/J0es1ick/shortli/internal/app/handlers/importHandlers"
+	"github.com/J0es1ick/shortli/internal/app/handlers/streamHandlers"
 	"github.com/J0es1ick/shortli/internal/app/handlers/urlHandlers"
+	"github.com/J0es1ick/shortli/internal/app/handlers/webhookHandlers"
+	"github.com/J0es1ick/shortli/internal/app/handlers/workspaceHandlers"
+	"github.com/J0es1ick/shortli/internal/app/health"
+	"github.com/J0es1ick/shortli/internal/app/importer"
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/app/middleware"
+	"github.com/J0es1ick/shortli/internal/app/service"
+	"github.com/J0es1ick/shortli/internal/app/stream"
 	"github.com/J0es1ick/shortli/internal/config"
+	"github.com/J0es1ick/shortli/internal/models"
 	"github.com/J0es1ick/shortli/internal/repository"
+	"github.com/J0es1ick/shortli/pkg/useragent"
+	"github.com/J0es1ick/shortli/pkg/validator"
 )
 
-func SetupRoutes(cfg *config.Config, urlRepository *repository.UrlRepository) http.Handler {
+type Dependencies struct {
+	UrlRepository       *repository.UrlRepository
+	ClickRepository     *repository.ClickRepository
+	WebhookRepository   *repository.WebhookRepository
+	UserRepository      *repository.UserRepository
+	WorkspaceRepository *repository.WorkspaceRepository
+	Health              *health.Checker
+	Validator           *validator.Validator
+	TargetValidator     *validator.Validator
+	Links               *service.LinkService
+	Classifier          *useragent.Classifier
+	Hub                 *stream.Hub
+	Analytics           *analytics.Service
+}
+
+func SetupRoutes(cfg *config.Config, deps Dependencies) http.Handler {
 	mux := http.NewServeMux()
 
-	urlHandler := urlHandlers.NewHandler(cfg, urlRepository)
+	urlHandler := urlHandlers.NewHandler(cfg, deps.Links, deps.Classifier)
+	webhookHandler := webhookHandlers.NewHandler(deps.WebhookRepository, deps.TargetValidator)
+	exportHandler := exportHandlers.NewHandler(deps.UrlRepository, deps.ClickRepository)
+	importHandler := importHandlers.NewHandler(importer.NewImporter(deps.UrlRepository, deps.Links, deps.Validator))
+	workspaceHandler := workspaceHandlers.NewHandler(deps.WorkspaceRepository)
+	streamHandler := streamHandlers.NewHandler(deps.Hub, deps.UrlRepository)
+	analyticsHandler := analyticsHandlers.NewHandler(deps.Analytics)
+
+	authn := middleware.NewAuth(deps.UserRepository, deps.WorkspaceRepository)
+	viewer := func(h http.HandlerFunc) http.HandlerFunc { return authn.Require(models.RoleViewer, h) }
+	editor := func(h http.HandlerFunc) http.HandlerFunc { return authn.Require(models.RoleEditor, h) }
+	owner := func(h http.HandlerFunc) http.HandlerFunc { return authn.Require(models.RoleOwner, h) }
     
+    mux.HandleFunc("GET /healthz", deps.Health.Liveness)
+    mux.HandleFunc("GET /readyz", deps.Health.Readiness)
+    mux.Handle("GET /metrics", metrics.Handler())
+
     mux.HandleFunc("GET /", urlHandler.Home)
-    mux.HandleFunc("POST /api/shorten", urlHandler.Shorten)
-    mux.HandleFunc("GET /api/stats/{shortCode}", urlHandler.UrlStats)
-	mux.HandleFunc("GET /api/stats", urlHandler.Stats)
+    mux.HandleFunc("POST /api/shorten", editor(urlHandler.Shorten))
+    mux.HandleFunc("GET /api/stats/{shortCode}", viewer(urlHandler.UrlStats))
+    mux.HandleFunc("GET /api/stats/{shortCode}/timeseries", viewer(urlHandler.TimeSeries))
+	mux.HandleFunc("GET /api/stats", viewer(urlHandler.Stats))
+	mux.HandleFunc("GET /api/overview", viewer(analyticsHandler.Overview))
     mux.HandleFunc("GET /{shortCode}", urlHandler.Redirect)
-    mux.HandleFunc("DELETE /urls/{shortCode}", urlHandler.Delete)
+    mux.HandleFunc("PATCH /urls/{shortCode}", editor(urlHandler.Update))
+    mux.HandleFunc("DELETE /urls/{shortCode}", editor(urlHandler.Delete))
+    mux.HandleFunc("POST /urls/{shortCode}/restore", editor(urlHandler.Restore))
+    mux.HandleFunc("POST /urls/{shortCode}/metadata/refresh", editor(urlHandler.RefreshMetadata))
+    mux.HandleFunc("GET /api/trash", viewer(urlHandler.Trash))
+
+    mux.HandleFunc("GET /api/stream/clicks", viewer(streamHandler.Workspace))
+    mux.HandleFunc("GET /api/stream/clicks/{shortCode}", viewer(streamHandler.Link))
+
+    mux.HandleFunc("POST /api/webhooks", editor(webhookHandler.Create))
+    mux.HandleFunc("GET /api/webhooks", viewer(webhookHandler.List))
+    mux.HandleFunc("DELETE /api/webhooks/{id}", editor(webhookHandler.Delete))
+    mux.HandleFunc("GET /api/webhooks/{id}/deliveries", viewer(webhookHandler.Deliveries))
+    mux.HandleFunc("POST /api/webhooks/{id}/deliveries/{deliveryId}/redeliver", editor(webhookHandler.Redeliver))
+
+    mux.HandleFunc("GET /api/export/links", viewer(exportHandler.Links))
+    mux.HandleFunc("GET /api/export/clicks", viewer(exportHandler.Clicks))
+    mux.HandleFunc("POST /api/import", editor(importHandler.Import))
+
+    mux.HandleFunc("GET /api/workspaces", authn.Authenticated(workspaceHandler.List))
+    mux.HandleFunc("POST /api/workspaces", authn.Authenticated(workspaceHandler.Create))
+    mux.HandleFunc("GET /api/workspaces/{workspaceId}/members", viewer(workspaceHandler.Members))
+    mux.HandleFunc("PATCH /api/workspaces/{workspaceId}/members/{userId}", owner(workspaceHandler.UpdateMember))
+    mux.HandleFunc("DELETE /api/workspaces/{workspaceId}/members/{userId}", viewer(workspaceHandler.RemoveMember))
+    mux.HandleFunc("GET /api/workspaces/{workspaceId}/invitations", owner(workspaceHandler.Invitations))
+    mux.HandleFunc("POST /api/workspaces/{workspaceId}/invitations", owner(workspaceHandler.Invite))
+    mux.HandleFunc("DELETE /api/workspaces/{workspaceId}/invitations/{invitationId}", owner(workspaceHandler.RevokeInvitation))
+    mux.HandleFunc("POST /api/invitations/{token}/accept", authn.Authenticated(workspaceHandler.AcceptInvitation))
 
 	return mux
 }
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/app/stream"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/pkg/useragent"
+)
+
+// Click describes a visit to a short link as seen by the transport.
+type Click struct {
+	Class     useragent.Class
+	Reason    string
+	IPAddress string
+	UserAgent string
+	Referrer  string
+	Country   string
+	At        time.Time
+}
+
+// RecordClick counts a visit to url. Only human clicks count towards the
+// click count, webhooks and unique visitors; every click is stored and
+// streamed. Only a failure to count a human click is returned, the rest is
+// best effort.
+func (s *LinkService) RecordClick(ctx context.Context, url *models.URL, click Click) error {
+	if click.At.IsZero() {
+		click.At = time.Now()
+	}
+	metrics.Clicks.WithLabelValues(string(click.Class)).Inc()
+
+	if click.Class == useragent.Human {
+		clickCount, err := s.urlRepository.IncrementClicks(ctx, url.ID)
+		if err != nil {
+			return err
+		}
+		url.ClickCount = clickCount
+		s.dispatcher.Publish(url.WorkspaceID, models.EventLinkClicked, url)
+		s.visitors.Track(ctx, url.ID, click.IPAddress, click.UserAgent, click.At)
+	} else {
+		slog.DebugContext(ctx, "Click not counted", "short_code", url.ShortCode, "class", click.Class, "reason", click.Reason)
+		if err := s.urlRepository.IncrementBotClicks(ctx, url.ID); err != nil {
+			slog.ErrorContext(ctx, "Failed to record bot click", "short_code", url.ShortCode, "error", err)
+		}
+	}
+
+	event := &models.ClickEvent{
+		URLID:          url.ID,
+		ShortCode:      url.ShortCode,
+		Referrer:       click.Referrer,
+		UserAgent:      click.UserAgent,
+		IPAddress:      click.IPAddress,
+		ClickedAt:      click.At,
+		Classification: string(click.Class),
+		Country:        click.Country,
+	}
+	if err := s.clickRepository.SaveClick(event); err != nil {
+		slog.ErrorContext(ctx, "Failed to record click", "short_code", url.ShortCode, "error", err)
+	}
+	s.hub.Publish(stream.Click{
+		URLID:          url.ID,
+		ShortCode:      url.ShortCode,
+		WorkspaceID:    url.WorkspaceID,
+		Referrer:       event.Referrer,
+		UserAgent:      event.UserAgent,
+		Classification: event.Classification,
+		Country:        event.Country,
+		ClickedAt:      event.ClickedAt,
+	})
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("link not found")
+
+	// ErrCodeExhausted means no free short code was found within the retry
+	// budget.
+	ErrCodeExhausted = errors.New("failed to generate unique short code")
+
+	ErrConflict = errors.New("link already exists")
+
+	// ErrDisabled means an operator took the link down.
+	ErrDisabled = errors.New("link disabled")
+)
+
+// ValidationError reports input that the caller has to fix.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// IsValidation reports whether err is caused by invalid input.
+func IsValidation(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
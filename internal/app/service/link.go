@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/app/stream"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/pkg/shortener"
+	"github.com/J0es1ick/shortli/pkg/validator"
+)
+
+const (
+	maxCodeAttempts = 5
+
+	maxOGTitleLength       = 300
+	maxOGDescriptionLength = 1000
+)
+
+// UrlRepository stores links; *repository.UrlRepository implements it.
+type UrlRepository interface {
+	SaveUrl(ctx context.Context, url *models.URL) (int64, error)
+	FindAllUrl(ctx context.Context, workspaceID int, tags []string, limit, offset int) ([]models.URL, error)
+	GetTotalUrls(ctx context.Context, workspaceID int, tags []string) (int, error)
+	FindUrlByCode(ctx context.Context, code string) (*models.URL, error)
+	FindWorkspaceUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error)
+	FindUrlByCanonicalHash(ctx context.Context, workspaceID, ownerID int, canonicalHash, originalUrl string) (*models.URL, error)
+	UpdateUrlByCode(ctx context.Context, url *models.URL) error
+	IncrementClicks(ctx context.Context, urlID int) (int, error)
+	IncrementBotClicks(ctx context.Context, urlID int) error
+	IsCodeReserved(ctx context.Context, code string) (bool, error)
+	DeleteUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error)
+	RestoreUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error)
+	FindDeletedUrls(ctx context.Context, workspaceID, limit, offset int) ([]models.URL, error)
+	GetTotalDeletedUrls(ctx context.Context, workspaceID int) (int, error)
+}
+
+// ClickRepository stores click events; *repository.ClickRepository
+// implements it.
+type ClickRepository interface {
+	SaveClick(click *models.ClickEvent) error
+	ClickSeries(ctx context.Context, urlID int, from, to time.Time, unit, tz string, useDaily bool) ([]models.ClickBucket, error)
+}
+
+// MetadataRepository loads the fetched metadata of links;
+// *repository.MetadataRepository implements it.
+type MetadataRepository interface {
+	FindMetadata(ctx context.Context, urlID int) (*models.LinkMetadata, error)
+	FindMetadataByURLs(ctx context.Context, urlIDs []int) (map[int]*models.LinkMetadata, error)
+}
+
+// Dispatcher queues webhook events; *webhooks.Dispatcher implements it.
+type Dispatcher interface {
+	Publish(workspaceID int, event string, data interface{})
+}
+
+// Enricher queues metadata fetches; *metadata.Enricher implements it.
+type Enricher interface {
+	Request(ctx context.Context, url *models.URL)
+}
+
+// VisitorTracker counts unique visitors; *visitors.Tracker implements it.
+type VisitorTracker interface {
+	Track(ctx context.Context, urlID int, ip, userAgent string, at time.Time)
+	UniqueVisitors(ctx context.Context, urlID int, from, to time.Time) (int64, error)
+	UniqueVisitorsBy(ctx context.Context, urlID int, from, to time.Time, group func(day time.Time) time.Time) (map[int64]int64, error)
+}
+
+// Hub streams clicks to live subscribers; *stream.Hub implements it.
+type Hub interface {
+	Publish(click stream.Click)
+}
+
+// LinkService holds the rules for creating and managing short links so that
+// every transport applies the same validation and dedup.
+type LinkService struct {
+	urlRepository      UrlRepository
+	clickRepository    ClickRepository
+	metadataRepository MetadataRepository
+	dispatcher         Dispatcher
+	validator          *validator.Validator
+	enricher           Enricher
+	visitors           VisitorTracker
+	hub                Hub
+}
+
+func NewLinkService(urlRepository UrlRepository, clickRepository ClickRepository, metadataRepository MetadataRepository, dispatcher Dispatcher, validator *validator.Validator, enricher Enricher, visitors VisitorTracker, hub Hub) *LinkService {
+	return &LinkService{
+		urlRepository:      urlRepository,
+		clickRepository:    clickRepository,
+		metadataRepository: metadataRepository,
+		dispatcher:         dispatcher,
+		validator:          validator,
+		enricher:           enricher,
+		visitors:           visitors,
+		hub:                hub,
+	}
+}
+
+// Preview overrides the social preview of a link. On update a nil field is
+// left unchanged and an empty string clears it.
+type Preview struct {
+	Title       *string
+	Description *string
+	ImageURL    *string
+}
+
+func (p Preview) isSet() bool {
+	return p.Title != nil || p.Description != nil || p.ImageURL != nil
+}
+
+type ShortenInput struct {
+	OriginalURL string
+	Tags        []string
+	// ReuseExisting returns the caller's existing link for the same URL
+	// instead of creating a new one. Defaults to true.
+	ReuseExisting *bool
+	Preview       Preview
+}
+
+type ShortenResult struct {
+	URL *models.URL
+	// Created is false when an existing link was reused.
+	Created bool
+}
+
+type UpdateInput struct {
+	// OriginalURL is left unchanged when empty.
+	OriginalURL string
+	Preview     Preview
+}
+
+type LinkStats struct {
+	URL            *models.URL
+	UniqueVisitors int64
+}
+
+type LinkPage struct {
+	Links []models.URL
+	Total int
+}
+
+// Shorten creates a link in the caller's workspace, or returns the caller's
+// existing link to the same canonical URL.
+func (s *LinkService) Shorten(ctx context.Context, principal *auth.Principal, in ShortenInput) (*ShortenResult, error) {
+	if in.OriginalURL == "" {
+		return nil, invalid("Required original_url")
+	}
+
+	normalizedURL, err := s.validator.Validate(in.OriginalURL)
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+
+	canonicalHash, err := s.validator.CanonicalHash(normalizedURL)
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+
+	// Dedup is scoped to the caller, so members never receive each other's
+	// links and can edit or delete their own independently.
+	if in.ReuseExisting == nil || *in.ReuseExisting {
+		existingURL, err := s.urlRepository.FindUrlByCanonicalHash(ctx, principal.WorkspaceID, principal.UserID(), canonicalHash, normalizedURL)
+		if err == nil {
+			existingURL.Metadata = s.findMetadata(ctx, existingURL.ID)
+			return &ShortenResult{URL: existingURL}, nil
+		}
+		if !strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+	}
+
+	shortCode, err := s.generateCode(ctx, normalizedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url := &models.URL{
+		OriginalURL:   normalizedURL,
+		ShortCode:     shortCode,
+		UserId:        principal.UserID(),
+		WorkspaceID:   principal.WorkspaceID,
+		ClickCount:    0,
+		CreatedAt:     time.Now(),
+		Tags:          in.Tags,
+		CanonicalHash: canonicalHash,
+	}
+	if err := s.applyPreview(url, in.Preview); err != nil {
+		return nil, err
+	}
+
+	id, err := s.urlRepository.SaveUrl(ctx, url)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return nil, ErrConflict
+		}
+		return nil, err
+	}
+	url.ID = int(id)
+	s.dispatcher.Publish(url.WorkspaceID, models.EventLinkCreated, url)
+	s.enricher.Request(ctx, url)
+
+	url.Metadata = &models.LinkMetadata{Status: models.MetadataStatusPending, RequestedAt: url.CreatedAt}
+	return &ShortenResult{URL: url, Created: true}, nil
+}
+
+// Import stores a link read from a foreign export as is, keeping its short
+// code, creation date and click total, and announces it the way Shorten
+// does: link.created is published and a metadata fetch is queued. The
+// caller validates the link; a code that is already taken returns
+// ErrConflict.
+func (s *LinkService) Import(ctx context.Context, url *models.URL) error {
+	id, err := s.urlRepository.SaveUrl(ctx, url)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return ErrConflict
+		}
+		return err
+	}
+	url.ID = int(id)
+	s.dispatcher.Publish(url.WorkspaceID, models.EventLinkCreated, url)
+	s.enricher.Request(ctx, url)
+
+	return nil
+}
+
+// generateCode derives a short code from originalURL and falls back to
+// random codes while the candidate is taken.
+func (s *LinkService) generateCode(ctx context.Context, originalURL string) (string, error) {
+	for attempt := 0; attempt <= maxCodeAttempts; attempt++ {
+		shortCode := shortener.GenerateShortCode(originalURL, attempt)
+
+		// Codes of trashed and purged links stay reserved so that an old
+		// short link never starts pointing somewhere else.
+		reserved, err := s.urlRepository.IsCodeReserved(ctx, shortCode)
+		if err != nil {
+			return "", err
+		}
+		if !reserved {
+			return shortCode, nil
+		}
+
+		metrics.ShortenCollisions.Inc()
+	}
+
+	return "", ErrCodeExhausted
+}
+
+// Resolve returns the active link behind shortCode in any workspace, or
+// ErrDisabled if it was taken down.
+func (s *LinkService) Resolve(ctx context.Context, shortCode string) (*models.URL, error) {
+	url, err := s.urlRepository.FindUrlByCode(ctx, shortCode)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if url.DisabledAt != nil {
+		return nil, ErrDisabled
+	}
+	return url, nil
+}
+
+// Get returns a link of the caller's workspace.
+func (s *LinkService) Get(ctx context.Context, principal *auth.Principal, shortCode string) (*models.URL, error) {
+	url, err := s.urlRepository.FindWorkspaceUrlByCode(ctx, principal.WorkspaceID, shortCode)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return url, nil
+}
+
+// Stats returns a link of the caller's workspace with its metadata and
+// unique visitor estimate. Visitor estimation is best effort.
+func (s *LinkService) Stats(ctx context.Context, principal *auth.Principal, shortCode string) (*LinkStats, error) {
+	url, err := s.Get(ctx, principal, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Metadata = s.findMetadata(ctx, url.ID)
+
+	uniqueVisitors, err := s.visitors.UniqueVisitors(ctx, url.ID, time.Time{}, time.Time{})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to estimate unique visitors", "short_code", url.ShortCode, "error", err)
+	}
+
+	return &LinkStats{URL: url, UniqueVisitors: uniqueVisitors}, nil
+}
+
+// List returns a page of the active links of the caller's workspace, only
+// those carrying all of tags if any are given. A page past the last link is
+// empty.
+func (s *LinkService) List(ctx context.Context, principal *auth.Principal, tags []string, limit, offset int) (*LinkPage, error) {
+	urls, err := s.urlRepository.FindAllUrl(ctx, principal.WorkspaceID, tags, limit, offset)
+	if err != nil {
+		if !strings.Contains(err.Error(), "no URLs found") && !strings.Contains(err.Error(), "not found") {
+			return nil, err
+		}
+		urls = []models.URL{}
+	}
+
+	s.attachMetadata(ctx, urls)
+
+	total, err := s.urlRepository.GetTotalUrls(ctx, principal.WorkspaceID, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LinkPage{Links: urls, Total: total}, nil
+}
+
+// Update changes the destination and/or the preview of a link.
+func (s *LinkService) Update(ctx context.Context, principal *auth.Principal, shortCode string, in UpdateInput) (*models.URL, error) {
+	if in.OriginalURL == "" && !in.Preview.isSet() {
+		return nil, invalid("Required original_url")
+	}
+
+	var normalizedURL, canonicalHash string
+	if in.OriginalURL != "" {
+		var err error
+		normalizedURL, err = s.validator.Validate(in.OriginalURL)
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+
+		canonicalHash, err = s.validator.CanonicalHash(normalizedURL)
+		if err != nil {
+			return nil, invalid(err.Error())
+		}
+	}
+
+	url, err := s.Get(ctx, principal, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := normalizedURL != "" && url.OriginalURL != normalizedURL
+	if normalizedURL != "" {
+		url.OriginalURL = normalizedURL
+		url.CanonicalHash = canonicalHash
+	}
+	if err := s.applyPreview(url, in.Preview); err != nil {
+		return nil, err
+	}
+
+	if err := s.urlRepository.UpdateUrlByCode(ctx, url); err != nil {
+		return nil, err
+	}
+	s.dispatcher.Publish(url.WorkspaceID, models.EventLinkUpdated, url)
+	if changed {
+		s.enricher.Request(ctx, url)
+	}
+
+	return url, nil
+}
+
+// Delete moves a link of the caller's workspace to the trash.
+func (s *LinkService) Delete(ctx context.Context, principal *auth.Principal, shortCode string) (*models.URL, error) {
+	url, err := s.urlRepository.DeleteUrlByCode(ctx, principal.WorkspaceID, shortCode)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	s.dispatcher.Publish(url.WorkspaceID, models.EventLinkDeleted, url)
+
+	return url, nil
+}
+
+// Trash returns a page of the trashed links of the caller's workspace.
+func (s *LinkService) Trash(ctx context.Context, principal *auth.Principal, limit, offset int) (*LinkPage, error) {
+	urls, err := s.urlRepository.FindDeletedUrls(ctx, principal.WorkspaceID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.urlRepository.GetTotalDeletedUrls(ctx, principal.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LinkPage{Links: urls, Total: total}, nil
+}
+
+// Restore moves a link of the caller's workspace out of the trash.
+func (s *LinkService) Restore(ctx context.Context, principal *auth.Principal, shortCode string) (*models.URL, error) {
+	url, err := s.urlRepository.RestoreUrlByCode(ctx, principal.WorkspaceID, shortCode)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	s.dispatcher.Publish(url.WorkspaceID, models.EventLinkRestored, url)
+
+	return url, nil
+}
+
+// RefreshMetadata queues a new fetch of the destination page metadata of a
+// link of the caller's workspace.
+func (s *LinkService) RefreshMetadata(ctx context.Context, principal *auth.Principal, shortCode string) (*models.URL, error) {
+	url, err := s.Get(ctx, principal, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	s.enricher.Request(ctx, url)
+
+	return url, nil
+}
+
+// applyPreview copies the preview fields onto url.
+func (s *LinkService) applyPreview(url *models.URL, preview Preview) error {
+	if preview.Title != nil {
+		title, err := previewText(*preview.Title, "og_title", maxOGTitleLength)
+		if err != nil {
+			return err
+		}
+		url.OGTitle = title
+	}
+	if preview.Description != nil {
+		description, err := previewText(*preview.Description, "og_description", maxOGDescriptionLength)
+		if err != nil {
+			return err
+		}
+		url.OGDescription = description
+	}
+	if preview.ImageURL != nil {
+		url.OGImageURL = nil
+		if image := strings.TrimSpace(*preview.ImageURL); image != "" {
+			normalized, err := s.validator.Validate(image)
+			if err != nil {
+				return invalid(fmt.Sprintf("invalid og_image_url: %v", err))
+			}
+			url.OGImageURL = &normalized
+		}
+	}
+	return nil
+}
+
+func previewText(value, field string, limit int) (*string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(value) > limit {
+		return nil, invalid(fmt.Sprintf("%s must be at most %d characters", field, limit))
+	}
+	return &value, nil
+}
+
+// notFound maps the repository's "not found" errors to ErrNotFound.
+func notFound(err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,551 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/app/stream"
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/J0es1ick/shortli/pkg/useragent"
+	"github.com/J0es1ick/shortli/pkg/validator"
+)
+
+// fakeUrls is an in-memory UrlRepository that returns the same errors as
+// the Postgres one.
+type fakeUrls struct {
+	byCode map[string]*models.URL
+	nextID int
+}
+
+func (f *fakeUrls) SaveUrl(ctx context.Context, url *models.URL) (int64, error) {
+	if _, ok := f.byCode[url.ShortCode]; ok {
+		return 0, fmt.Errorf("url with this code already exists")
+	}
+	f.nextID++
+	saved := *url
+	saved.ID = f.nextID
+	f.byCode[url.ShortCode] = &saved
+	return int64(saved.ID), nil
+}
+
+func (f *fakeUrls) active(workspaceID int) []models.URL {
+	var urls []models.URL
+	for _, url := range f.byCode {
+		if url.WorkspaceID == workspaceID && url.DeletedAt == nil {
+			urls = append(urls, *url)
+		}
+	}
+	return urls
+}
+
+func (f *fakeUrls) FindAllUrl(ctx context.Context, workspaceID int, tags []string, limit, offset int) ([]models.URL, error) {
+	urls := f.active(workspaceID)
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no URLs found")
+	}
+	return urls, nil
+}
+
+func (f *fakeUrls) GetTotalUrls(ctx context.Context, workspaceID int, tags []string) (int, error) {
+	return len(f.active(workspaceID)), nil
+}
+
+func (f *fakeUrls) FindUrlByCode(ctx context.Context, code string) (*models.URL, error) {
+	url, ok := f.byCode[code]
+	if !ok || url.DeletedAt != nil {
+		return nil, fmt.Errorf("url not found")
+	}
+	found := *url
+	return &found, nil
+}
+
+func (f *fakeUrls) FindWorkspaceUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error) {
+	url, err := f.FindUrlByCode(ctx, code)
+	if err != nil || url.WorkspaceID != workspaceID {
+		return nil, fmt.Errorf("url not found")
+	}
+	return url, nil
+}
+
+func (f *fakeUrls) FindUrlByCanonicalHash(ctx context.Context, workspaceID, ownerID int, canonicalHash, originalUrl string) (*models.URL, error) {
+	for _, url := range f.byCode {
+		if url.WorkspaceID == workspaceID && url.UserId == ownerID && url.CanonicalHash == canonicalHash && url.DeletedAt == nil {
+			found := *url
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("url not found")
+}
+
+func (f *fakeUrls) UpdateUrlByCode(ctx context.Context, url *models.URL) error {
+	if _, ok := f.byCode[url.ShortCode]; !ok {
+		return fmt.Errorf("no rows updated - url with code '%s' not found", url.ShortCode)
+	}
+	// Like the Postgres repository, an update never writes the counters.
+	updated := *url
+	updated.ClickCount = f.byCode[url.ShortCode].ClickCount
+	updated.BotClickCount = f.byCode[url.ShortCode].BotClickCount
+	f.byCode[url.ShortCode] = &updated
+	return nil
+}
+
+func (f *fakeUrls) IncrementClicks(ctx context.Context, urlID int) (int, error) {
+	for _, url := range f.byCode {
+		if url.ID == urlID {
+			url.ClickCount++
+			return url.ClickCount, nil
+		}
+	}
+	return 0, fmt.Errorf("url not found")
+}
+
+func (f *fakeUrls) IncrementBotClicks(ctx context.Context, urlID int) error {
+	for _, url := range f.byCode {
+		if url.ID == urlID {
+			url.BotClickCount++
+		}
+	}
+	return nil
+}
+
+func (f *fakeUrls) IsCodeReserved(ctx context.Context, code string) (bool, error) {
+	_, ok := f.byCode[code]
+	return ok, nil
+}
+
+func (f *fakeUrls) DeleteUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error) {
+	url, ok := f.byCode[code]
+	if !ok || url.WorkspaceID != workspaceID || url.DeletedAt != nil {
+		return nil, fmt.Errorf("url with code '%s' not found", code)
+	}
+	now := time.Now()
+	url.DeletedAt = &now
+	deleted := *url
+	return &deleted, nil
+}
+
+func (f *fakeUrls) RestoreUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error) {
+	url, ok := f.byCode[code]
+	if !ok || url.WorkspaceID != workspaceID || url.DeletedAt == nil {
+		return nil, fmt.Errorf("deleted url with code '%s' not found", code)
+	}
+	url.DeletedAt = nil
+	restored := *url
+	return &restored, nil
+}
+
+func (f *fakeUrls) FindDeletedUrls(ctx context.Context, workspaceID, limit, offset int) ([]models.URL, error) {
+	urls := []models.URL{}
+	for _, url := range f.byCode {
+		if url.WorkspaceID == workspaceID && url.DeletedAt != nil {
+			urls = append(urls, *url)
+		}
+	}
+	return urls, nil
+}
+
+func (f *fakeUrls) GetTotalDeletedUrls(ctx context.Context, workspaceID int) (int, error) {
+	urls, _ := f.FindDeletedUrls(ctx, workspaceID, 0, 0)
+	return len(urls), nil
+}
+
+type fakeClicks struct {
+	saved   []*models.ClickEvent
+	buckets []models.ClickBucket
+	tz      string
+}
+
+func (f *fakeClicks) SaveClick(click *models.ClickEvent) error {
+	f.saved = append(f.saved, click)
+	return nil
+}
+
+func (f *fakeClicks) ClickSeries(ctx context.Context, urlID int, from, to time.Time, unit, tz string, useDaily bool) ([]models.ClickBucket, error) {
+	f.tz = tz
+	return f.buckets, nil
+}
+
+type fakeMetadata struct{}
+
+func (fakeMetadata) FindMetadata(ctx context.Context, urlID int) (*models.LinkMetadata, error) {
+	return nil, fmt.Errorf("metadata not found")
+}
+
+func (fakeMetadata) FindMetadataByURLs(ctx context.Context, urlIDs []int) (map[int]*models.LinkMetadata, error) {
+	return map[int]*models.LinkMetadata{}, nil
+}
+
+type fakeDispatcher struct {
+	events []string
+}
+
+func (f *fakeDispatcher) Publish(workspaceID int, event string, data interface{}) {
+	f.events = append(f.events, event)
+}
+
+type fakeEnricher struct {
+	requested int
+}
+
+func (f *fakeEnricher) Request(ctx context.Context, url *models.URL) {
+	f.requested++
+}
+
+type fakeVisitors struct {
+	tracked []string
+	uniques map[int64]int64
+}
+
+func (f *fakeVisitors) Track(ctx context.Context, urlID int, ip, userAgent string, at time.Time) {
+	f.tracked = append(f.tracked, ip)
+}
+
+func (f *fakeVisitors) UniqueVisitors(ctx context.Context, urlID int, from, to time.Time) (int64, error) {
+	return int64(len(f.tracked)), nil
+}
+
+func (f *fakeVisitors) UniqueVisitorsBy(ctx context.Context, urlID int, from, to time.Time, group func(day time.Time) time.Time) (map[int64]int64, error) {
+	return f.uniques, nil
+}
+
+type fakeHub struct {
+	clicks []stream.Click
+}
+
+func (f *fakeHub) Publish(click stream.Click) {
+	f.clicks = append(f.clicks, click)
+}
+
+type fixture struct {
+	links      *LinkService
+	urls       *fakeUrls
+	clicks     *fakeClicks
+	dispatcher *fakeDispatcher
+	enricher   *fakeEnricher
+	visitors   *fakeVisitors
+	hub        *fakeHub
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		urls:       &fakeUrls{byCode: map[string]*models.URL{}},
+		clicks:     &fakeClicks{},
+		dispatcher: &fakeDispatcher{},
+		enricher:   &fakeEnricher{},
+		visitors:   &fakeVisitors{},
+		hub:        &fakeHub{},
+	}
+	f.links = NewLinkService(f.urls, f.clicks, fakeMetadata{}, f.dispatcher, validator.New(nil), f.enricher, f.visitors, f.hub)
+	return f
+}
+
+func principal(userID, workspaceID int) *auth.Principal {
+	return &auth.Principal{User: &models.User{ID: userID}, WorkspaceID: workspaceID, Role: models.RoleOwner}
+}
+
+func TestShortenDedup(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	alice, bob := principal(1, 10), principal(2, 10)
+
+	first, err := f.links.Shorten(ctx, alice, ShortenInput{OriginalURL: "https://example.com/page"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Created {
+		t.Error("first Shorten did not create a link")
+	}
+
+	again, err := f.links.Shorten(ctx, alice, ShortenInput{OriginalURL: "https://EXAMPLE.com/page"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Created || again.URL.ShortCode != first.URL.ShortCode {
+		t.Errorf("Shorten of the same URL = %q (created %v), want reuse of %q", again.URL.ShortCode, again.Created, first.URL.ShortCode)
+	}
+
+	fresh := false
+	forced, err := f.links.Shorten(ctx, alice, ShortenInput{OriginalURL: "https://example.com/page", ReuseExisting: &fresh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !forced.Created || forced.URL.ShortCode == first.URL.ShortCode {
+		t.Errorf("Shorten with ReuseExisting=false = %q (created %v), want a new link", forced.URL.ShortCode, forced.Created)
+	}
+
+	other, err := f.links.Shorten(ctx, bob, ShortenInput{OriginalURL: "https://example.com/page"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.Created {
+		t.Error("Shorten by another member reused a link it does not own")
+	}
+
+	if got := len(f.dispatcher.events); got != 3 {
+		t.Errorf("published %d events, want 3", got)
+	}
+	if f.enricher.requested != 3 {
+		t.Errorf("requested metadata %d times, want 3", f.enricher.requested)
+	}
+}
+
+func TestShortenValidation(t *testing.T) {
+	f := newFixture()
+
+	for _, input := range []string{"", "not a url", "ftp://example.com"} {
+		_, err := f.links.Shorten(context.Background(), principal(1, 10), ShortenInput{OriginalURL: input})
+		if !IsValidation(err) {
+			t.Errorf("Shorten(%q) error = %v, want a validation error", input, err)
+		}
+	}
+	if len(f.urls.byCode) != 0 {
+		t.Error("invalid input was stored")
+	}
+}
+
+func TestImport(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	createdAt := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	url := &models.URL{OriginalURL: "https://example.com/", ShortCode: "legacy", WorkspaceID: 10, ClickCount: 42, CreatedAt: createdAt}
+	if err := f.links.Import(ctx, url); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := f.urls.byCode["legacy"]
+	if url.ID == 0 || stored.ClickCount != 42 || !stored.CreatedAt.Equal(createdAt) {
+		t.Errorf("stored %+v, want the imported code, clicks and creation date", stored)
+	}
+	if len(f.dispatcher.events) != 1 || f.dispatcher.events[0] != models.EventLinkCreated {
+		t.Errorf("published %v, want %s", f.dispatcher.events, models.EventLinkCreated)
+	}
+	if f.enricher.requested != 1 {
+		t.Errorf("requested metadata %d times, want 1", f.enricher.requested)
+	}
+
+	again := &models.URL{OriginalURL: "https://example.org/", ShortCode: "legacy", WorkspaceID: 10}
+	if err := f.links.Import(ctx, again); !errors.Is(err, ErrConflict) {
+		t.Errorf("Import of a taken code = %v, want ErrConflict", err)
+	}
+	if len(f.dispatcher.events) != 1 || f.enricher.requested != 1 {
+		t.Error("a conflicting import was announced")
+	}
+}
+
+func TestRecordClick(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	result, err := f.links.Shorten(ctx, principal(1, 10), ShortenInput{OriginalURL: "https://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.dispatcher.events = nil
+
+	url, err := f.links.Resolve(ctx, result.URL.ShortCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.links.RecordClick(ctx, url, Click{Class: useragent.Human, IPAddress: "203.0.113.7"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.links.RecordClick(ctx, url, Click{Class: useragent.Bot, IPAddress: "198.51.100.2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := f.urls.byCode[result.URL.ShortCode]
+	if stored.ClickCount != 1 || stored.BotClickCount != 1 {
+		t.Errorf("clicks = %d human, %d bot, want 1 and 1", stored.ClickCount, stored.BotClickCount)
+	}
+	if len(f.dispatcher.events) != 1 || f.dispatcher.events[0] != models.EventLinkClicked {
+		t.Errorf("events = %v, want only %s", f.dispatcher.events, models.EventLinkClicked)
+	}
+	if len(f.visitors.tracked) != 1 || f.visitors.tracked[0] != "203.0.113.7" {
+		t.Errorf("tracked visitors = %v, want only the human", f.visitors.tracked)
+	}
+	if len(f.clicks.saved) != 2 || len(f.hub.clicks) != 2 {
+		t.Errorf("saved %d and streamed %d clicks, want 2 and 2", len(f.clicks.saved), len(f.hub.clicks))
+	}
+	for _, click := range f.clicks.saved {
+		if click.ClickedAt.IsZero() {
+			t.Error("click saved without a time")
+		}
+	}
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	owner := principal(1, 10)
+
+	result, err := f.links.Shorten(ctx, owner, ShortenInput{OriginalURL: "https://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := result.URL.ShortCode
+
+	if _, err := f.links.Delete(ctx, principal(1, 20), code); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete from another workspace error = %v, want ErrNotFound", err)
+	}
+	if _, err := f.links.Delete(ctx, owner, code); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.links.Resolve(ctx, code); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve of a trashed link error = %v, want ErrNotFound", err)
+	}
+
+	trash, err := f.links.Trash(ctx, owner, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trash.Total != 1 || trash.Links[0].ShortCode != code {
+		t.Errorf("Trash = %+v, want the deleted link", trash)
+	}
+	page, err := f.links.List(ctx, owner, nil, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 0 || len(page.Links) != 0 {
+		t.Errorf("List = %+v, want no active links", page)
+	}
+
+	if _, err := f.links.Restore(ctx, owner, code); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.links.Resolve(ctx, code); err != nil {
+		t.Errorf("Resolve of a restored link error = %v", err)
+	}
+	if _, err := f.links.Restore(ctx, owner, code); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Restore of an active link error = %v, want ErrNotFound", err)
+	}
+
+	want := []string{models.EventLinkCreated, models.EventLinkDeleted, models.EventLinkRestored}
+	if fmt.Sprint(f.dispatcher.events) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", f.dispatcher.events, want)
+	}
+}
+
+func TestSeries(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	owner := principal(1, 10)
+
+	result, err := f.links.Shorten(ctx, owner, ShortenInput{OriginalURL: "https://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	f.clicks.buckets = []models.ClickBucket{
+		{Bucket: day(2), Clicks: 5, BotClicks: 1},
+		{Bucket: day(4), Clicks: 2},
+	}
+	f.visitors.uniques = map[int64]int64{day(2).Unix(): 3}
+
+	series, err := f.links.Series(ctx, owner, result.URL.ShortCode, SeriesInput{
+		From: day(1),
+		To:   day(4).Add(12 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !series.From.Equal(day(1)) || !series.To.Equal(day(5)) {
+		t.Errorf("range = %v to %v, want whole days %v to %v", series.From, series.To, day(1), day(5))
+	}
+	if len(series.Buckets) != 4 {
+		t.Fatalf("got %d buckets, want 4", len(series.Buckets))
+	}
+	wantClicks := []int64{0, 5, 0, 2}
+	for i, bucket := range series.Buckets {
+		if !bucket.Bucket.Equal(day(i + 1)) {
+			t.Errorf("bucket %d starts at %v, want %v", i, bucket.Bucket, day(i+1))
+		}
+		if bucket.Clicks != wantClicks[i] {
+			t.Errorf("bucket %d has %d clicks, want %d", i, bucket.Clicks, wantClicks[i])
+		}
+		if bucket.UniqueVisitors == nil {
+			t.Errorf("bucket %d has no unique visitors", i)
+		}
+	}
+	if *series.Buckets[1].UniqueVisitors != 3 {
+		t.Errorf("unique visitors = %d, want 3", *series.Buckets[1].UniqueVisitors)
+	}
+	if series.Clicks != 7 || series.BotClicks != 1 {
+		t.Errorf("totals = %d human, %d bot, want 7 and 1", series.Clicks, series.BotClicks)
+	}
+
+	if _, err := f.links.Series(ctx, owner, result.URL.ShortCode, SeriesInput{Interval: "minute"}); !IsValidation(err) {
+		t.Errorf("Series with an unknown interval error = %v, want a validation error", err)
+	}
+	if _, err := f.links.Series(ctx, owner, "missing", SeriesInput{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Series of a missing link error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRecordClickConcurrentCopies(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	owner := principal(1, 10)
+
+	result, err := f.links.Shorten(ctx, owner, ShortenInput{OriginalURL: "https://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := result.URL.ShortCode
+
+	// Two redirects resolve the link before either records its click.
+	first, _ := f.links.Resolve(ctx, code)
+	second, _ := f.links.Resolve(ctx, code)
+	for _, url := range []*models.URL{first, second} {
+		if err := f.links.RecordClick(ctx, url, Click{Class: useragent.Human}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	title := "Example"
+	if _, err := f.links.Update(ctx, owner, code, UpdateInput{Preview: Preview{Title: &title}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.urls.byCode[code].ClickCount; got != 2 {
+		t.Errorf("ClickCount = %d, want 2", got)
+	}
+	if second.ClickCount != 2 {
+		t.Errorf("RecordClick left ClickCount = %d on the link, want 2", second.ClickCount)
+	}
+}
+
+func TestSeriesLocation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	owner := principal(1, 10)
+
+	result, err := f.links.Shorten(ctx, owner, ShortenInput{OriginalURL: "https://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := result.URL.ShortCode
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	if _, err := f.links.Series(ctx, owner, code, SeriesInput{Location: berlin}); err != nil {
+		t.Fatal(err)
+	}
+	if f.clicks.tz != "Europe/Berlin" {
+		t.Errorf("ClickSeries got tz %q, want Europe/Berlin", f.clicks.tz)
+	}
+
+	for _, loc := range []*time.Location{time.Local, time.FixedZone("", 3600)} {
+		if _, err := f.links.Series(ctx, owner, code, SeriesInput{Location: loc}); !IsValidation(err) {
+			t.Errorf("Series in %q error = %v, want a validation error", loc, err)
+		}
+	}
+}
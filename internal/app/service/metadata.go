@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+// findMetadata returns the metadata of urlID, or nil if none was requested
+// or it cannot be loaded; metadata is never essential to a response.
+func (s *LinkService) findMetadata(ctx context.Context, urlID int) *models.LinkMetadata {
+	linkMetadata, err := s.metadataRepository.FindMetadata(ctx, urlID)
+	if err != nil {
+		if !strings.Contains(err.Error(), "not found") {
+			slog.ErrorContext(ctx, "Failed to load link metadata", "url_id", urlID, "error", err)
+		}
+		return nil
+	}
+	return linkMetadata
+}
+
+func (s *LinkService) attachMetadata(ctx context.Context, urls []models.URL) {
+	ids := make([]int, len(urls))
+	for i, url := range urls {
+		ids[i] = url.ID
+	}
+
+	byURL, err := s.metadataRepository.FindMetadataByURLs(ctx, ids)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load link metadata", "error", err)
+		return
+	}
+
+	for i := range urls {
+		urls[i].Metadata = byURL[urls[i].ID]
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/skip2/go-qrcode"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/J0es1ick/shortli/internal/app/service")
+
+// QRCode renders a PNG QR code for url.
+func (s *LinkService) QRCode(ctx context.Context, url *models.URL) ([]byte, error) {
+	_, span := tracer.Start(ctx, "qrcode.Encode")
+	defer span.End()
+
+	png, err := qrcode.Encode(url.OriginalURL, qrcode.Low, 150)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return png, err
+}
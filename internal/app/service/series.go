@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/auth"
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+// maxSeriesBuckets bounds the points of a single time series.
+const maxSeriesBuckets = 2000
+
+var defaultSeriesRange = map[string]time.Duration{
+	"hour":  7 * 24 * time.Hour,
+	"day":   30 * 24 * time.Hour,
+	"week":  26 * 7 * 24 * time.Hour,
+	"month": 365 * 24 * time.Hour,
+}
+
+type SeriesInput struct {
+	// Interval is hour, day, week or month. Defaults to day.
+	Interval string
+	// Location defaults to UTC. It must be named after an IANA zone, which
+	// rules out time.Local and fixed zones.
+	Location *time.Location
+	// From and To default to a range that depends on the interval, ending
+	// now. Both are widened to whole buckets.
+	From time.Time
+	To   time.Time
+}
+
+type Series struct {
+	URL       *models.URL
+	Interval  string
+	Location  *time.Location
+	From      time.Time
+	To        time.Time
+	Buckets   []models.ClickBucket
+	Clicks    int64
+	BotClicks int64
+}
+
+// Series returns the clicks of a link of the caller's workspace per
+// interval, with a bucket for every interval in the range.
+func (s *LinkService) Series(ctx context.Context, principal *auth.Principal, shortCode string, in SeriesInput) (*Series, error) {
+	interval := in.Interval
+	if interval == "" {
+		interval = "day"
+	}
+	if _, ok := defaultSeriesRange[interval]; !ok {
+		return nil, invalid("interval must be hour, day, week or month")
+	}
+
+	loc := in.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	// Postgres resolves the zone by name and knows neither the server's
+	// local zone nor unnamed ones.
+	if name := loc.String(); name == "" || name == "Local" {
+		return nil, invalid("tz must be an IANA time zone name such as Europe/Berlin")
+	}
+
+	to := in.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := in.From
+	if from.IsZero() {
+		from = to.Add(-defaultSeriesRange[interval])
+	}
+
+	from = truncateBucket(from, interval, loc)
+	if start := truncateBucket(to, interval, loc); start.Before(to) {
+		to = nextBucket(start, interval)
+	}
+	if !from.Before(to) {
+		return nil, invalid("from must be before to")
+	}
+
+	var starts []time.Time
+	for bucket := from; bucket.Before(to); bucket = nextBucket(bucket, interval) {
+		if len(starts) == maxSeriesBuckets {
+			return nil, invalid(fmt.Sprintf("Range exceeds %d buckets, use a larger interval", maxSeriesBuckets))
+		}
+		starts = append(starts, bucket)
+	}
+
+	url, err := s.Get(ctx, principal, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	useDaily := loc == time.UTC && interval != "hour"
+	series, err := s.clickRepository.ClickSeries(ctx, url.ID, from, to, interval, loc.String(), useDaily)
+	if err != nil {
+		return nil, err
+	}
+
+	// Visitor sketches are kept per UTC day, so hourly buckets have no
+	// unique visitor counts.
+	var uniques map[int64]int64
+	if interval != "hour" {
+		uniques, err = s.visitors.UniqueVisitorsBy(ctx, url.ID, from, to.Add(-time.Nanosecond), func(day time.Time) time.Time {
+			return truncateBucket(time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc), interval, loc)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	counts := make(map[int64]models.ClickBucket, len(series))
+	for _, bucket := range series {
+		counts[bucket.Bucket.Unix()] = bucket
+	}
+
+	result := &Series{
+		URL:      url,
+		Interval: interval,
+		Location: loc,
+		From:     from,
+		To:       to,
+		Buckets:  make([]models.ClickBucket, 0, len(starts)),
+	}
+	for _, start := range starts {
+		bucket := counts[start.Unix()]
+		bucket.Bucket = start
+		if uniques != nil {
+			unique := uniques[start.Unix()]
+			bucket.UniqueVisitors = &unique
+		}
+
+		result.Clicks += bucket.Clicks
+		result.BotClicks += bucket.BotClicks
+		result.Buckets = append(result.Buckets, bucket)
+	}
+
+	return result, nil
+}
+
+// truncateBucket returns the start of the bucket containing t in loc. Weeks
+// start on Monday, as in Postgres date_trunc.
+func truncateBucket(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+
+	switch interval {
+	case "hour":
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, loc)
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, loc)
+	case "month":
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+func nextBucket(start time.Time, interval string) time.Time {
+	year, month, day := start.Date()
+
+	switch interval {
+	case "hour":
+		return start.Add(time.Hour)
+	case "week":
+		return time.Date(year, month, day+7, 0, 0, 0, 0, start.Location())
+	case "month":
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, start.Location())
+	}
+	return time.Date(year, month, day+1, 0, 0, 0, 0, start.Location())
+}
@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const subscriberBuffer = 64
+
+// Click is a redirect as seen by live stream subscribers. It deliberately
+// leaves out the visitor's IP address.
+type Click struct {
+	ID             uint64    `json:"id"`
+	URLID          int       `json:"url_id"`
+	ShortCode      string    `json:"short_code"`
+	WorkspaceID    int       `json:"workspace_id"`
+	Referrer       string    `json:"referrer,omitempty"`
+	UserAgent      string    `json:"user_agent,omitempty"`
+	Classification string    `json:"classification"`
+	Country        string    `json:"country,omitempty"`
+	ClickedAt      time.Time `json:"clicked_at"`
+}
+
+// Filter selects the clicks a subscriber receives. A zero URLID matches
+// every link of the workspace.
+type Filter struct {
+	WorkspaceID int
+	URLID       int
+	IncludeBots bool
+}
+
+func (f Filter) matches(click *Click) bool {
+	if click.WorkspaceID != f.WorkspaceID {
+		return false
+	}
+	if f.URLID != 0 && click.URLID != f.URLID {
+		return false
+	}
+	return f.IncludeBots || click.Classification == "human"
+}
+
+type Subscription struct {
+	hub     *Hub
+	filter  Filter
+	events  chan Click
+	dropped atomic.Int64
+}
+
+// Events delivers matching clicks. It is closed when the subscription is
+// cancelled or the hub is closed.
+func (s *Subscription) Events() <-chan Click {
+	return s.events
+}
+
+// Dropped returns and resets the number of clicks dropped since the last
+// call because the subscriber was not keeping up.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+func (s *Subscription) Cancel() {
+	s.hub.remove(s)
+}
+
+// Hub fans clicks out to live stream subscribers in this process. Publish
+// never blocks: a subscriber whose buffer is full misses the click and is
+// told how many it missed.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	nextID      atomic.Uint64
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber. It returns nil once the hub is closed.
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+
+	sub := &Subscription{hub: h, filter: filter, events: make(chan Click, subscriberBuffer)}
+	h.subscribers[sub] = struct{}{}
+	metrics.StreamSubscribers.Inc()
+	return sub
+}
+
+func (h *Hub) Publish(click Click) {
+	click.ID = h.nextID.Add(1)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers {
+		if !sub.filter.matches(&click) {
+			continue
+		}
+
+		select {
+		case sub.events <- click:
+		default:
+			sub.dropped.Add(1)
+			metrics.StreamDropped.Inc()
+		}
+	}
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	close(sub.events)
+	metrics.StreamSubscribers.Dec()
+}
+
+// Close ends every subscription so that open streams return, which lets
+// server shutdown complete.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subscribers {
+		delete(h.subscribers, sub)
+		close(sub.events)
+		metrics.StreamSubscribers.Dec()
+	}
+}
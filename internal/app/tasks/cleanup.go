@@ -1,21 +1,47 @@
 package tasks
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/app/webhooks"
+	"github.com/J0es1ick/shortli/internal/models"
 	"github.com/J0es1ick/shortli/internal/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = otel.Tracer("github.com/J0es1ick/shortli/internal/app/tasks")
+
 type CleanupTask struct {
 	urlRepository 	*repository.UrlRepository
+	dispatcher 		*webhooks.Dispatcher
 	interval 		time.Duration
+	retention 		time.Duration
+
+	mu          sync.Mutex
+	created     time.Time
+	lastRun     time.Time
+	lastErr     error
+	lastSuccess time.Time
+}
+
+type CleanupResult struct {
+	Archived int64
+	Purged   int64
 }
 
-func NewCleanupTask(urlRepository *repository.UrlRepository, interval time.Duration) *CleanupTask {
+func NewCleanupTask(urlRepository *repository.UrlRepository, dispatcher *webhooks.Dispatcher, interval, retention time.Duration) *CleanupTask {
 	return &CleanupTask{
 		urlRepository: urlRepository,
+		dispatcher: dispatcher,
 		interval: interval,
+		retention: retention,
+		created: time.Now(),
 	}
 }
 
@@ -29,21 +55,89 @@ func (c *CleanupTask) Start() {
 }
 
 func (c *CleanupTask) runCleanup() {
-	log.Println("Starting cleanup of old URLs...")
+	slog.Info("Starting cleanup of old URLs")
     
-    count, err := c.urlRepository.DeleteOldUrls()
+    result, err := c.RunOnce(context.Background())
     if err != nil {
-        log.Printf("Cleanup failed: %v", err)
+        slog.Error("Cleanup failed", "error", err)
         return
     }
     
-    if count > 0 {
-        log.Printf("Cleanup completed: deleted %d old URLs", count)
-    } else {
-        log.Println("Cleanup completed: no old URLs found")
+    slog.Info("Cleanup completed", "archived", result.Archived, "purged", result.Purged)
+}
+
+// RunOnce moves expired links to the trash and then purges trash entries
+// older than the retention period.
+func (t *CleanupTask) RunOnce(ctx context.Context) (result CleanupResult, err error) {
+    ctx, span := tracer.Start(ctx, "CleanupTask.RunOnce")
+    defer func() {
+        span.SetAttributes(
+            attribute.Int64("cleanup.archived", result.Archived),
+            attribute.Int64("cleanup.purged", result.Purged),
+        )
+        if err != nil {
+            span.RecordError(err)
+            span.SetStatus(codes.Error, err.Error())
+        }
+        span.End()
+    }()
+    defer t.recordRun(&err)
+
+    var archived []models.URL
+    archived, err = t.urlRepository.ArchiveOldUrls(ctx)
+    if err != nil {
+        return result, err
+    }
+    result.Archived = int64(len(archived))
+    metrics.CleanupLinks.WithLabelValues("archived").Add(float64(result.Archived))
+
+    for _, url := range archived {
+        t.dispatcher.Publish(url.WorkspaceID, models.EventLinkExpired, url)
     }
+
+    purged, err := t.urlRepository.PurgeDeletedUrls(ctx, t.retention)
+    if err != nil {
+        return result, err
+    }
+    result.Purged = purged
+    metrics.CleanupLinks.WithLabelValues("purged").Add(float64(purged))
+
+    return result, nil
 }
 
-func (t *CleanupTask) RunOnce() (int64, error) {
-    return t.urlRepository.DeleteOldUrls()
-}
\ No newline at end of file
+// DryRun reports what RunOnce would archive and purge without changing
+// anything.
+func (t *CleanupTask) DryRun(ctx context.Context) (CleanupResult, error) {
+    archived, purged, err := t.urlRepository.CountCleanupCandidates(ctx, t.retention)
+    if err != nil {
+        return CleanupResult{}, err
+    }
+
+    return CleanupResult{Archived: archived, Purged: purged}, nil
+}
+
+func (t *CleanupTask) recordRun(err *error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    t.lastRun = time.Now()
+    t.lastErr = *err
+
+    if *err != nil {
+        metrics.CleanupRuns.WithLabelValues("failure").Inc()
+        return
+    }
+    t.lastSuccess = t.lastRun
+    metrics.CleanupRuns.WithLabelValues("success").Inc()
+    metrics.CleanupLastSuccess.SetToCurrentTime()
+}
+
+// Check fails when no run has succeeded for twice the interval, counted
+// from the creation of the task. A single failed run is retried at the next
+// interval and does not fail the check on its own.
+func (t *CleanupTask) Check() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    return checkStale("cleanup", t.created, t.lastSuccess, t.lastErr, 2*t.interval)
+}
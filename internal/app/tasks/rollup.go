@@ -0,0 +1,127 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	rollupBatch  = 10000
+	rollupSettle = 30 * time.Second
+)
+
+// RollupTask aggregates raw click events into the hourly and daily rollups
+// that back the time-series stats.
+type RollupTask struct {
+	clickRepository *repository.ClickRepository
+	interval        time.Duration
+
+	mu          sync.Mutex
+	created     time.Time
+	lastRun     time.Time
+	lastErr     error
+	lastSuccess time.Time
+}
+
+func NewRollupTask(clickRepository *repository.ClickRepository, interval time.Duration) *RollupTask {
+	return &RollupTask{
+		clickRepository: clickRepository,
+		interval:        interval,
+		created:         time.Now(),
+	}
+}
+
+func (t *RollupTask) Start() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := t.RunOnce(context.Background()); err != nil {
+			slog.Error("Click rollup failed", "error", err)
+		}
+	}
+}
+
+// RunOnce folds every settled click event into the rollups, batch by batch,
+// and returns how many were folded.
+func (t *RollupTask) RunOnce(ctx context.Context) (total int64, err error) {
+	ctx, span := tracer.Start(ctx, "RollupTask.RunOnce")
+	defer func() {
+		span.SetAttributes(attribute.Int64("rollup.events", total))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	defer t.recordRun(&err)
+
+	for {
+		var n int64
+		n, err = t.clickRepository.RollupClicks(ctx, rollupBatch, rollupSettle)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		metrics.RollupEvents.Add(float64(n))
+
+		if n < rollupBatch {
+			return total, nil
+		}
+	}
+}
+
+func (t *RollupTask) recordRun(err *error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastRun = time.Now()
+	t.lastErr = *err
+
+	if *err != nil {
+		metrics.RollupRuns.WithLabelValues("failure").Inc()
+		return
+	}
+	t.lastSuccess = t.lastRun
+	metrics.RollupRuns.WithLabelValues("success").Inc()
+	metrics.RollupLastSuccess.SetToCurrentTime()
+}
+
+// Check fails when no run has succeeded for twice the interval, counted
+// from the creation of the task. A single failed run is retried at the next
+// interval and does not fail the check on its own.
+func (t *RollupTask) Check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return checkStale("rollup", t.created, t.lastSuccess, t.lastErr, 2*t.interval)
+}
+
+// checkStale fails when the last success of a task, or its creation if it
+// never succeeded, is older than limit.
+func checkStale(task string, created, lastSuccess time.Time, lastErr error, limit time.Duration) error {
+	since := lastSuccess
+	if since.IsZero() {
+		since = created
+	}
+	if time.Since(since) <= limit {
+		return nil
+	}
+
+	err := fmt.Errorf("last successful %s at %s", task, since.Format(time.RFC3339))
+	if lastSuccess.IsZero() {
+		err = fmt.Errorf("no successful %s since start at %s", task, since.Format(time.RFC3339))
+	}
+	if lastErr != nil {
+		err = fmt.Errorf("%v, last run failed: %v", err, lastErr)
+	}
+	return err
+}
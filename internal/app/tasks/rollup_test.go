@@ -0,0 +1,34 @@
+package tasks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckStale(t *testing.T) {
+	now := time.Now()
+	failed := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		created     time.Time
+		lastSuccess time.Time
+		lastErr     error
+		wantErr     bool
+	}{
+		{"never ran, just started", now.Add(-time.Hour), time.Time{}, nil, false},
+		{"first run failed", now.Add(-time.Hour), time.Time{}, failed, false},
+		{"never succeeded", now.Add(-3 * time.Hour), time.Time{}, failed, true},
+		{"failed after a recent success", now.Add(-48 * time.Hour), now.Add(-time.Hour), failed, false},
+		{"no recent success", now.Add(-48 * time.Hour), now.Add(-3 * time.Hour), failed, true},
+		{"stopped running", now.Add(-48 * time.Hour), now.Add(-3 * time.Hour), nil, true},
+	}
+
+	for _, tt := range tests {
+		err := checkStale("rollup", tt.created, tt.lastSuccess, tt.lastErr, 2*time.Hour)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkStale() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package visitors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/repository"
+	"github.com/J0es1ick/shortli/pkg/hll"
+)
+
+// saltRetention is how many days of salts are kept. Yesterday's salt must
+// survive midnight so that clicks buffered just before it still flush.
+const saltRetention = 2
+
+type sketchKey struct {
+	urlID int
+	day   time.Time
+}
+
+// Tracker counts approximate unique visitors per link and UTC day.
+//
+// A visitor is identified by a hash of their IP address and User-Agent
+// salted with a random value that changes every day and is deleted after
+// saltRetention days, so stored sketches can't be linked back to a visitor
+// or across days. Counts over several days therefore count a returning
+// visitor once per day.
+//
+// Visits are buffered in memory and merged into the stored sketches every
+// interval, and on Flush.
+type Tracker struct {
+	visitorRepository *repository.VisitorRepository
+	interval          time.Duration
+
+	mu      sync.Mutex
+	salts   map[time.Time][]byte
+	pending map[sketchKey]*hll.Sketch
+}
+
+func NewTracker(visitorRepository *repository.VisitorRepository, interval time.Duration) *Tracker {
+	return &Tracker{
+		visitorRepository: visitorRepository,
+		interval:          interval,
+		salts:             make(map[time.Time][]byte),
+		pending:           make(map[sketchKey]*hll.Sketch),
+	}
+}
+
+// Track records a visit to urlID. Failures are logged rather than returned
+// so that tracking never fails a redirect.
+func (t *Tracker) Track(ctx context.Context, urlID int, ip, userAgent string, at time.Time) {
+	day := Day(at)
+
+	salt, err := t.salt(ctx, day)
+	if err != nil {
+		slog.ErrorContext(ctx, "Visitor salt lookup failed", "error", err)
+		return
+	}
+
+	hash := sha256.New()
+	hash.Write(salt)
+	hash.Write([]byte(hostAddress(ip)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(userAgent))
+	visitor := binary.BigEndian.Uint64(hash.Sum(nil))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := sketchKey{urlID: urlID, day: day}
+	sketch, ok := t.pending[key]
+	if !ok {
+		sketch, _ = hll.New(hll.DefaultPrecision)
+		t.pending[key] = sketch
+	}
+	sketch.Add(visitor)
+}
+
+// hostAddress strips the port from ip so that a visitor opening several
+// connections is counted once.
+func hostAddress(ip string) string {
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		return addr.Unmap().String()
+	}
+	return ip
+}
+
+func (t *Tracker) salt(ctx context.Context, day time.Time) ([]byte, error) {
+	t.mu.Lock()
+	salt, ok := t.salts[day]
+	t.mu.Unlock()
+	if ok {
+		return salt, nil
+	}
+
+	candidate := make([]byte, 32)
+	if _, err := rand.Read(candidate); err != nil {
+		return nil, err
+	}
+
+	salt, err := t.visitorRepository.SaltForDay(ctx, day, candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.salts[day] = salt
+	for cached := range t.salts {
+		if cached.Before(day.AddDate(0, 0, -saltRetention+1)) {
+			delete(t.salts, cached)
+		}
+	}
+	t.mu.Unlock()
+
+	return salt, nil
+}
+
+func (t *Tracker) Start() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := t.Flush(context.Background()); err != nil {
+			slog.Error("Visitor sketch flush failed", "error", err)
+		}
+	}
+}
+
+// Flush merges buffered visits into the stored sketches and forgets expired
+// salts. Sketches that fail to save are kept for the next flush.
+func (t *Tracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[sketchKey]*hll.Sketch)
+	t.mu.Unlock()
+
+	var firstErr error
+	for key, sketch := range pending {
+		err := t.visitorRepository.MergeSketch(ctx, key.urlID, key.day, func(stored []byte) ([]byte, int64, error) {
+			merged, err := hll.New(sketch.Precision())
+			if err != nil {
+				return nil, 0, err
+			}
+			if stored != nil {
+				if err := merged.UnmarshalBinary(stored); err != nil {
+					return nil, 0, err
+				}
+			}
+			if err := merged.Merge(sketch); err != nil {
+				return nil, 0, err
+			}
+
+			data, err := merged.MarshalBinary()
+			return data, int64(merged.Estimate()), err
+		})
+		if err != nil {
+			t.requeue(key, sketch)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if _, err := t.visitorRepository.DeleteSaltsBefore(ctx, Day(time.Now()).AddDate(0, 0, -saltRetention+1)); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	return firstErr
+}
+
+func (t *Tracker) requeue(key sketchKey, sketch *hll.Sketch) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if current, ok := t.pending[key]; ok {
+		current.Merge(sketch)
+		return
+	}
+	t.pending[key] = sketch
+}
+
+// UniqueVisitors estimates the distinct visitors of urlID between the days
+// of from and to, inclusive, including visits not flushed yet. A zero from
+// or to leaves that end open.
+func (t *Tracker) UniqueVisitors(ctx context.Context, urlID int, from, to time.Time) (int64, error) {
+	estimates, err := t.UniqueVisitorsBy(ctx, urlID, from, to, func(time.Time) time.Time { return time.Time{} })
+	if err != nil {
+		return 0, err
+	}
+
+	return estimates[time.Time{}.Unix()], nil
+}
+
+// UniqueVisitorsBy estimates distinct visitors of urlID between the days of
+// from and to per group, where group maps a UTC day to its group key. The
+// result is keyed by the group's Unix time.
+func (t *Tracker) UniqueVisitorsBy(ctx context.Context, urlID int, from, to time.Time, group func(day time.Time) time.Time) (map[int64]int64, error) {
+	sketches, err := t.visitorRepository.FindSketches(ctx, urlID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	unions := make(map[int64]*hll.Sketch)
+	merge := func(day time.Time, sketch *hll.Sketch) error {
+		key := group(day).Unix()
+		union, ok := unions[key]
+		if !ok {
+			union, _ = hll.New(hll.DefaultPrecision)
+			unions[key] = union
+		}
+		return union.Merge(sketch)
+	}
+
+	for _, stored := range sketches {
+		var sketch hll.Sketch
+		if err := sketch.UnmarshalBinary(stored.Sketch); err != nil {
+			return nil, err
+		}
+		if err := merge(stored.Day, &sketch); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	for key, sketch := range t.pending {
+		if key.urlID == urlID && inRange(key.day, from, to) {
+			merge(key.day, sketch)
+		}
+	}
+	t.mu.Unlock()
+
+	estimates := make(map[int64]int64, len(unions))
+	for key, union := range unions {
+		estimates[key] = int64(union.Estimate())
+	}
+	return estimates, nil
+}
+
+func inRange(day, from, to time.Time) bool {
+	return (from.IsZero() || !day.Before(Day(from))) && (to.IsZero() || !day.After(Day(to)))
+}
+
+// Day returns the UTC day containing t.
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
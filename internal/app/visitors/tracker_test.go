@@ -0,0 +1,27 @@
+package visitors
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTrackCountsOneVisitorAcrossPorts(t *testing.T) {
+	tracker := NewTracker(nil, time.Minute)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker.salts[Day(at)] = []byte("salt")
+
+	ua := "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+	for _, ip := range []string{"203.0.113.7:51000", "203.0.113.7:51001", "203.0.113.7", "[::ffff:203.0.113.7]:443"} {
+		tracker.Track(context.Background(), 1, ip, ua, at)
+	}
+	tracker.Track(context.Background(), 1, "198.51.100.2:4000", ua, at)
+
+	sketch := tracker.pending[sketchKey{urlID: 1, day: Day(at)}]
+	if sketch == nil {
+		t.Fatal("no sketch recorded")
+	}
+	if got := sketch.Estimate(); got != 2 {
+		t.Errorf("Estimate() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,241 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/app/metrics"
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+const (
+	SignatureHeader = "X-Shortli-Signature"
+	TimestampHeader = "X-Shortli-Timestamp"
+	EventHeader     = "X-Shortli-Event"
+	DeliveryHeader  = "X-Shortli-Delivery"
+)
+
+const (
+	batchSize    = 50
+	maxAttempts  = 8
+	baseBackoff  = 30 * time.Second
+	maxBackoff   = 6 * time.Hour
+	claimLease   = 2 * time.Minute
+	bodyLogLimit = 64 << 10
+	// publishQueue bounds the events waiting to be stored before Publish
+	// starts dropping them.
+	publishQueue = 1024
+)
+
+type Event struct {
+	Type       string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Store persists webhook deliveries; *repository.WebhookRepository
+// implements it.
+type Store interface {
+	EnqueueDeliveries(workspaceID int, event string, payload []byte) (int64, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.PendingWebhookDelivery, error)
+	RecordAttempt(id int64, responseStatus int, attemptErr string, nextAttempt *time.Time) error
+}
+
+type published struct {
+	workspaceID int
+	event       string
+	payload     []byte
+}
+
+type Dispatcher struct {
+	webhookRepository Store
+	client            *http.Client
+	interval          time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan published
+	done   chan struct{}
+}
+
+func NewDispatcher(webhookRepository Store, client *http.Client, interval time.Duration) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	d := &Dispatcher{
+		webhookRepository: webhookRepository,
+		client:            client,
+		interval:          interval,
+		queue:             make(chan published, publishQueue),
+		done:              make(chan struct{}),
+	}
+	go d.store()
+	return d
+}
+
+// Publish queues event for every webhook of workspaceID subscribed to it.
+// Deliveries are stored in the background so that the request that
+// triggered the event, such as a redirect, never waits for the database.
+// Failures are logged rather than returned; when the queue is full the
+// event is dropped.
+func (d *Dispatcher) Publish(workspaceID int, event string, data interface{}) {
+	payload, err := json.Marshal(Event{
+		Type:       event,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		slog.Error("Webhook payload encoding failed", "event", event, "error", err)
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		slog.Error("Webhook event published after close", "event", event, "workspace_id", workspaceID)
+		return
+	}
+
+	select {
+	case d.queue <- published{workspaceID: workspaceID, event: event, payload: payload}:
+	default:
+		metrics.WebhooksDropped.Inc()
+		slog.Error("Webhook queue full, event dropped", "event", event, "workspace_id", workspaceID)
+	}
+}
+
+func (d *Dispatcher) store() {
+	defer close(d.done)
+
+	for p := range d.queue {
+		if _, err := d.webhookRepository.EnqueueDeliveries(p.workspaceID, p.event, p.payload); err != nil {
+			slog.Error("Webhook enqueue failed", "event", p.event, "workspace_id", p.workspaceID, "error", err)
+		}
+	}
+}
+
+// Close stores the events still queued by Publish and stops accepting new
+// ones.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	<-d.done
+}
+
+func (d *Dispatcher) Start() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := d.DeliverPending(context.Background()); err != nil {
+			slog.Error("Webhook delivery failed", "error", err)
+		}
+	}
+}
+
+// DeliverPending sends every delivery that is currently due and returns how
+// many were attempted.
+func (d *Dispatcher) DeliverPending(ctx context.Context) (int, error) {
+	deliveries, err := d.webhookRepository.ClaimDueDeliveries(batchSize, claimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		status, sendErr := d.send(ctx, &delivery)
+
+		var nextAttempt *time.Time
+		attemptErr := ""
+		if sendErr != nil {
+			attemptErr = sendErr.Error()
+			if delivery.Attempts+1 < maxAttempts {
+				next := time.Now().Add(Backoff(delivery.Attempts + 1))
+				nextAttempt = &next
+			}
+		}
+
+		if err := d.webhookRepository.RecordAttempt(delivery.ID, status, attemptErr, nextAttempt); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *models.PendingWebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Shortli-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, bodyLogLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+// Receivers recompute it to verify the X-Shortli-Signature header.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the given retry: 30s doubled per attempt,
+// capped at six hours.
+func Backoff(attempt int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
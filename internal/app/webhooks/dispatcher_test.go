@@ -0,0 +1,242 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+type attempt struct {
+	id             int64
+	responseStatus int
+	err            string
+	nextAttempt    *time.Time
+}
+
+// status mirrors how WebhookRepository.RecordAttempt derives the status of
+// a delivery from an attempt.
+func (a attempt) status() string {
+	if a.nextAttempt != nil {
+		return models.DeliveryPending
+	}
+	if a.err != "" {
+		return models.DeliveryDead
+	}
+	return models.DeliveryDelivered
+}
+
+type fakeStore struct {
+	mu       sync.Mutex
+	due      []models.PendingWebhookDelivery
+	attempts []attempt
+	enqueued []string
+	// block, if set, holds EnqueueDeliveries until it is closed.
+	block chan struct{}
+}
+
+func (s *fakeStore) EnqueueDeliveries(workspaceID int, event string, payload []byte) (int64, error) {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enqueued = append(s.enqueued, event)
+	return 1, nil
+}
+
+func (s *fakeStore) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.PendingWebhookDelivery, error) {
+	due := s.due
+	s.due = nil
+	return due, nil
+}
+
+func (s *fakeStore) RecordAttempt(id int64, responseStatus int, attemptErr string, nextAttempt *time.Time) error {
+	s.attempts = append(s.attempts, attempt{id: id, responseStatus: responseStatus, err: attemptErr, nextAttempt: nextAttempt})
+	return nil
+}
+
+func delivery(targetURL string, attempts int) models.PendingWebhookDelivery {
+	return models.PendingWebhookDelivery{
+		WebhookDelivery: models.WebhookDelivery{
+			ID:       42,
+			Event:    models.EventLinkCreated,
+			Payload:  `{"event":"link.created"}`,
+			Attempts: attempts,
+		},
+		TargetURL: targetURL,
+		Secret:    "whsec_test",
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"link.created"}`)
+	want := "157c90f250cb20ef0f8f798ef6b985d7bf78bcf43128ad5325212589883c33e8"
+
+	if got := Sign("whsec_test", 1700000000, body); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if Sign("whsec_test", 1700000001, body) == want {
+		t.Error("signature does not cover the timestamp")
+	}
+	if Sign("whsec_other", 1700000000, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{10, 4*time.Hour + 16*time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverPendingSignsRequest(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{due: []models.PendingWebhookDelivery{delivery(server.URL, 0)}}
+	d := NewDispatcher(store, server.Client(), time.Minute)
+	defer d.Close()
+
+	n, err := d.DeliverPending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("DeliverPending() = %d, want 1", n)
+	}
+
+	if string(body) != `{"event":"link.created"}` {
+		t.Errorf("body = %s", body)
+	}
+	if got := header.Get(EventHeader); got != models.EventLinkCreated {
+		t.Errorf("%s = %q", EventHeader, got)
+	}
+	if got := header.Get(DeliveryHeader); got != "42" {
+		t.Errorf("%s = %q, want 42", DeliveryHeader, got)
+	}
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("%s: %v", TimestampHeader, err)
+	}
+	if want := "sha256=" + Sign("whsec_test", timestamp, body); header.Get(SignatureHeader) != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, header.Get(SignatureHeader), want)
+	}
+
+	if len(store.attempts) != 1 {
+		t.Fatalf("recorded %d attempts, want 1", len(store.attempts))
+	}
+	if a := store.attempts[0]; a.status() != models.DeliveryDelivered || a.responseStatus != http.StatusNoContent {
+		t.Errorf("attempt = %+v, want delivered with status 204", a)
+	}
+}
+
+func TestDeliverPendingRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for previous := 0; previous < maxAttempts; previous++ {
+		store := &fakeStore{due: []models.PendingWebhookDelivery{delivery(server.URL, previous)}}
+		d := NewDispatcher(store, server.Client(), time.Minute)
+
+		before := time.Now()
+		if _, err := d.DeliverPending(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		d.Close()
+
+		a := store.attempts[0]
+		if a.responseStatus != http.StatusServiceUnavailable || a.err == "" {
+			t.Errorf("attempt %d = %+v, want a failure with status 503", previous+1, a)
+		}
+
+		if previous+1 == maxAttempts {
+			if a.status() != models.DeliveryDead {
+				t.Errorf("last attempt left the delivery %s, want %s", a.status(), models.DeliveryDead)
+			}
+			continue
+		}
+
+		if a.status() != models.DeliveryPending {
+			t.Fatalf("attempt %d left the delivery %s, want %s", previous+1, a.status(), models.DeliveryPending)
+		}
+		wait := a.nextAttempt.Sub(before)
+		if want := Backoff(previous + 1); wait < want || wait > want+time.Minute {
+			t.Errorf("attempt %d retries in %v, want %v", previous+1, wait, want)
+		}
+	}
+}
+
+func TestDeliverPendingUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	target := server.URL
+	server.Close()
+
+	store := &fakeStore{due: []models.PendingWebhookDelivery{delivery(target, maxAttempts-1)}}
+	d := NewDispatcher(store, nil, time.Minute)
+	defer d.Close()
+
+	if _, err := d.DeliverPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if a := store.attempts[0]; a.status() != models.DeliveryDead || a.responseStatus != 0 {
+		t.Errorf("attempt = %+v, want dead without a response status", a)
+	}
+}
+
+func TestPublishDoesNotBlock(t *testing.T) {
+	store := &fakeStore{block: make(chan struct{})}
+	d := NewDispatcher(store, nil, time.Minute)
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			d.Publish(1, models.EventLinkClicked, map[string]int{"click": i})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish waited for the store")
+	}
+
+	close(store.block)
+	d.Close()
+	if len(store.enqueued) != 10 {
+		t.Errorf("stored %d events, want 10", len(store.enqueued))
+	}
+
+	// Publishing after Close is dropped instead of panicking.
+	d.Publish(1, models.EventLinkClicked, nil)
+}
@@ -1,17 +1,37 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
+// Config is read from environment variables, which take precedence over an
+// optional config file, which takes precedence over the defaults below.
+// Every variable can instead be read from a file named by the same variable
+// with a _FILE suffix, such as DATABASE_PASSWORD_FILE, to pass secrets.
 type Config struct {
-	ServerPort string   `mapstructure:"SERVER_PORT"`
-	Database   Database `mapstructure:",squash"`
+	ServerPort         string    `mapstructure:"SERVER_PORT"`
+	// GRPCPort serves the gRPC API, which is off unless a port is set.
+	GRPCPort           string    `mapstructure:"GRPC_PORT"`
+	TrashRetentionDays int       `mapstructure:"TRASH_RETENTION_DAYS"`
+	LogFormat          string    `mapstructure:"LOG_FORMAT"`
+	LogLevel           string    `mapstructure:"LOG_LEVEL"`
+	ShutdownDrainSecs  int       `mapstructure:"SHUTDOWN_DRAIN_SECONDS"`
+	RedisURL           string    `mapstructure:"REDIS_URL"`
+	// CountryHeader names the request header in which a trusted edge proxy
+	// reports the visitor's country, such as CF-IPCountry.
+	CountryHeader      string    `mapstructure:"GEO_COUNTRY_HEADER"`
+	Database           Database  `mapstructure:",squash"`
+	Tracing            Tracing   `mapstructure:",squash"`
+	Screening          Screening `mapstructure:",squash"`
+	BotFilter          BotFilter `mapstructure:",squash"`
 }
 
 type Database struct {
@@ -22,26 +42,259 @@ type Database struct {
 	Name     string `mapstructure:"DATABASE_NAME"`
 }
 
-func InitConfig() (*Config, error) {
-	exePath, err := os.Getwd()
+type Tracing struct {
+	Exporter    string  `mapstructure:"TRACING_EXPORTER"`
+	Endpoint    string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	Insecure    bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+	SampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+}
+
+// Screening configures which destinations may be shortened. List values are
+// comma-separated; "*.example.com" matches every subdomain of example.com.
+type Screening struct {
+	DenyList       []string `mapstructure:"URL_DENYLIST"`
+	AllowList      []string `mapstructure:"URL_ALLOWLIST"`
+	ThreatListFile string   `mapstructure:"THREAT_LIST_FILE"`
+	ShortDomains   []string `mapstructure:"SHORT_DOMAINS"`
+
+	// Dedup-only canonicalization steps; stored URLs are never rewritten.
+	StripFragment bool `mapstructure:"CANONICAL_STRIP_FRAGMENT"`
+	StripTracking bool `mapstructure:"CANONICAL_STRIP_TRACKING"`
+}
+
+// BotFilter configures which redirects are not counted as human clicks on
+// top of the built-in User-Agent patterns and prefetch headers.
+type BotFilter struct {
+	UserAgents        []string `mapstructure:"BOT_USER_AGENTS"`
+	ScannerRangesFile string   `mapstructure:"SCANNER_RANGES_FILE"`
+}
+
+// defaults documents the default of every variable; .env.example lists
+// them all. An empty default means the feature is off or the value is
+// optional.
+var defaults = map[string]string{
+	"SERVER_PORT":            "8080",
+	"GRPC_PORT":              "",
+	"TRASH_RETENTION_DAYS":   "30",
+	"LOG_FORMAT":             "text",
+	"LOG_LEVEL":              "info",
+	"SHUTDOWN_DRAIN_SECONDS": "5",
+	"REDIS_URL":              "",
+	"GEO_COUNTRY_HEADER":     "",
+
+	"DATABASE_HOST":     "localhost",
+	"DATABASE_PORT":     "5432",
+	"DATABASE_USER":     "postgres",
+	"DATABASE_PASSWORD": "",
+	"DATABASE_NAME":     "shortli",
+
+	"TRACING_EXPORTER":      "none",
+	"TRACING_OTLP_ENDPOINT": "localhost:4318",
+	"TRACING_OTLP_INSECURE": "false",
+	"TRACING_SAMPLE_RATIO":  "1.0",
+
+	"URL_DENYLIST":             "",
+	"URL_ALLOWLIST":            "",
+	"THREAT_LIST_FILE":         "",
+	"SHORT_DOMAINS":            "",
+	"CANONICAL_STRIP_FRAGMENT": "false",
+	"CANONICAL_STRIP_TRACKING": "true",
+
+	"BOT_USER_AGENTS":     "",
+	"SCANNER_RANGES_FILE": "",
+}
+
+// InvalidError lists every problem found while loading the configuration.
+type InvalidError struct {
+	Problems []string
+}
+
+func (e *InvalidError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Load builds the configuration from the environment, the config file at
+// path and the defaults. path may be a YAML, TOML, JSON or .env file whose
+// keys are the variable names, in any case. Without a path a .env file is
+// read from the working directory or two directories above it, if present.
+// All invalid values are reported together in an *InvalidError.
+func Load(path string) (*Config, error) {
+	file, err := readFile(path)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	projectRoot := filepath.Dir(filepath.Dir(exePath))
-	
-	viper.SetConfigName(".env") 
-	viper.SetConfigType("env")  
-	viper.AddConfigPath(projectRoot)
+	var cfg Config
+	var problems []string
+	unparsed := map[string]bool{}
+	for _, field := range fields(reflect.ValueOf(&cfg).Elem()) {
+		raw, ok, err := lookup(field.key, file)
+		if err != nil {
+			problems = append(problems, err.Error())
+			unparsed[field.key] = true
+			continue
+		}
+		// Empty numbers and flags, as in a copied .env.example, fall back to
+		// their defaults; an empty string is a value of its own.
+		if !ok || (strings.TrimSpace(raw) == "" && field.value.Kind() != reflect.String && field.value.Kind() != reflect.Slice) {
+			raw = defaults[field.key]
+		}
 
-	if err = viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config: %w", err)
+		if err := set(field.value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field.key, err))
+			unparsed[field.key] = true
+		}
 	}
-	
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+
+	// Fields that could not be read are reported once, not again for their
+	// zero value.
+	for _, problem := range cfg.validate() {
+		key, _, _ := strings.Cut(problem, ":")
+		if !unparsed[key] {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &InvalidError{Problems: problems}
 	}
 
 	return &cfg, nil
-}
\ No newline at end of file
+}
+
+func readFile(path string) (*viper.Viper, error) {
+	v := viper.New()
+
+	if path != "" {
+		v.SetConfigFile(path)
+		if filepath.Base(path) == ".env" {
+			v.SetConfigType("env")
+		}
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		return v, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	v.SetConfigName(".env")
+	v.SetConfigType("env")
+	v.AddConfigPath(wd)
+	v.AddConfigPath(filepath.Dir(filepath.Dir(wd)))
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+
+	return v, nil
+}
+
+// lookup returns the value of key from KEY_FILE, KEY or the config file, in
+// that order. Surrounding spaces are trimmed, except from KEY_FILE, which
+// only loses the line ending that editors add, so that secrets keep any
+// spaces they have.
+func lookup(key string, file *viper.Viper) (string, bool, error) {
+	value, set := os.LookupEnv(key)
+
+	if path, ok := os.LookupEnv(key + "_FILE"); ok {
+		if set {
+			return "", false, fmt.Errorf("%s and %s_FILE are both set", key, key)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %v", key, err)
+		}
+		value = strings.TrimSuffix(string(content), "\n")
+		return strings.TrimSuffix(value, "\r"), true, nil
+	}
+
+	if set {
+		return strings.TrimSpace(value), true, nil
+	}
+
+	if file.IsSet(key) {
+		switch value := file.Get(key).(type) {
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			return strings.Join(items, ","), true, nil
+		case nil:
+			return "", true, nil
+		default:
+			return strings.TrimSpace(fmt.Sprint(value)), true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+// fields lists the settable fields of a config struct by variable name,
+// descending into squashed structs.
+func fields(v reflect.Value) []field {
+	var result []field
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i).Tag.Get("mapstructure")
+		if tag == ",squash" {
+			result = append(result, fields(v.Field(i))...)
+			continue
+		}
+		result = append(result, field{key: tag, value: v.Field(i)})
+	}
+	return result
+}
+
+// set parses raw into v. Strings are kept as they are; numbers and flags
+// may be surrounded by spaces.
+func set(v reflect.Value, raw string) error {
+	if v.Kind() != reflect.String {
+		raw = strings.TrimSpace(raw)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
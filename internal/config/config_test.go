@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// clearEnv unsets every variable Load reads for the duration of the test,
+// so that the environment of the machine running the tests does not leak in.
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	for key := range defaults {
+		for _, name := range []string{key, key + "_FILE"} {
+			if _, ok := os.LookupEnv(name); ok {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+		}
+	}
+}
+
+// writeFile writes content to name in a fresh directory and returns its
+// path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load(writeFile(t, ".env", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ServerPort != "8080" || cfg.TrashRetentionDays != 30 || cfg.LogLevel != "info" {
+		t.Errorf("Load() = %+v, want the defaults", cfg)
+	}
+	if cfg.GRPCPort != "" {
+		t.Errorf("GRPCPort = %q, want gRPC off by default", cfg.GRPCPort)
+	}
+	if cfg.Database.Host != "localhost" || cfg.Database.Password != "" {
+		t.Errorf("Database = %+v, want the defaults", cfg.Database)
+	}
+	if cfg.Tracing.SampleRatio != 1 || cfg.Tracing.Insecure {
+		t.Errorf("Tracing = %+v, want the defaults", cfg.Tracing)
+	}
+	if !cfg.Screening.StripTracking || cfg.Screening.StripFragment || cfg.Screening.DenyList != nil {
+		t.Errorf("Screening = %+v, want the defaults", cfg.Screening)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  string
+		// secret is the content of SERVER_PORT_FILE, if set.
+		secret string
+		want   string
+	}{
+		{"default", "", "", "", "8080"},
+		{"file", "7000", "", "", "7000"},
+		{"env over file", "7000", "7001", "", "7001"},
+		{"_FILE over file", "7000", "", "7002\n", "7002"},
+		{"_FILE over default", "", "", "7003", "7003"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+
+			content := ""
+			if tt.file != "" {
+				content = "SERVER_PORT = " + tt.file + "\n"
+			}
+			if tt.env != "" {
+				t.Setenv("SERVER_PORT", tt.env)
+			}
+			if tt.secret != "" {
+				t.Setenv("SERVER_PORT_FILE", writeFile(t, "port", tt.secret))
+			}
+
+			cfg, err := Load(writeFile(t, ".env", content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.ServerPort != tt.want {
+				t.Errorf("ServerPort = %q, want %q", cfg.ServerPort, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadStructuredFile(t *testing.T) {
+	clearEnv(t)
+
+	path := writeFile(t, "config.yaml", "server_port: 7000\nurl_denylist:\n  - a.example\n  - '*.b.example'\ntracing_sample_ratio: 0.5\n")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ServerPort != "7000" || cfg.Tracing.SampleRatio != 0.5 {
+		t.Errorf("Load() = %+v", cfg)
+	}
+	if want := []string{"a.example", "*.b.example"}; !slices.Equal(cfg.Screening.DenyList, want) {
+		t.Errorf("DenyList = %q, want %q", cfg.Screening.DenyList, want)
+	}
+}
+
+func TestLoadTrimming(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		secret string
+		want   string
+	}{
+		{"env", "  s3cret  ", "", "s3cret"},
+		{"_FILE keeps spaces", "", "  s3cret \n", "  s3cret "},
+		{"_FILE CRLF", "", "s3cret\r\n", "s3cret"},
+		{"_FILE without newline", "", "s3cret", "s3cret"},
+		{"_FILE only one newline", "", "s3cret\n\n", "s3cret\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			if tt.env != "" {
+				t.Setenv("DATABASE_PASSWORD", tt.env)
+			}
+			if tt.secret != "" {
+				t.Setenv("DATABASE_PASSWORD_FILE", writeFile(t, "password", tt.secret))
+			}
+
+			cfg, err := Load(writeFile(t, ".env", ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Database.Password != tt.want {
+				t.Errorf("Password = %q, want %q", cfg.Database.Password, tt.want)
+			}
+		})
+	}
+
+	clearEnv(t)
+	t.Setenv("TRASH_RETENTION_DAYS_FILE", writeFile(t, "days", " 7 \n"))
+	cfg, err := Load(writeFile(t, ".env", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TrashRetentionDays != 7 {
+		t.Errorf("TrashRetentionDays = %d, want 7", cfg.TrashRetentionDays)
+	}
+}
+
+func TestLoadEmptyValues(t *testing.T) {
+	clearEnv(t)
+
+	// As in a copied .env.example with some values removed.
+	path := writeFile(t, ".env", "TRASH_RETENTION_DAYS =\nTRACING_OTLP_INSECURE =\nGEO_COUNTRY_HEADER =\n")
+	t.Setenv("SHUTDOWN_DRAIN_SECONDS", "")
+	t.Setenv("TRACING_SAMPLE_RATIO", "  ")
+	t.Setenv("LOG_FORMAT", "")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.TrashRetentionDays != 30 || cfg.ShutdownDrainSecs != 5 || cfg.Tracing.SampleRatio != 1 || cfg.Tracing.Insecure {
+		t.Errorf("empty numbers and flags did not fall back to their defaults: %+v", cfg)
+	}
+	// An empty string is a value of its own.
+	if cfg.LogFormat != "" || cfg.CountryHeader != "" {
+		t.Errorf("LogFormat = %q, CountryHeader = %q, want both empty", cfg.LogFormat, cfg.CountryHeader)
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	clearEnv(t)
+
+	t.Setenv("SERVER_PORT", "8081")
+	t.Setenv("SERVER_PORT_FILE", writeFile(t, "port", "8082"))
+	t.Setenv("TRASH_RETENTION_DAYS", "thirty")
+	t.Setenv("TRACING_SAMPLE_RATIO", "2")
+	t.Setenv("DATABASE_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("LOG_LEVEL", "loud")
+
+	_, err := Load(writeFile(t, ".env", "TRACING_OTLP_INSECURE = maybe\n"))
+
+	var invalid *InvalidError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load() = %v, want an *InvalidError", err)
+	}
+
+	want := []string{
+		"SERVER_PORT and SERVER_PORT_FILE are both set",
+		"TRASH_RETENTION_DAYS: \"thirty\" is not an integer",
+		"LOG_LEVEL:",
+		"DATABASE_PASSWORD_FILE:",
+		"TRACING_OTLP_INSECURE: \"maybe\" is not a boolean",
+		"TRACING_SAMPLE_RATIO: must be between 0 and 1",
+	}
+	for _, fragment := range want {
+		n := 0
+		for _, problem := range invalid.Problems {
+			if strings.HasPrefix(problem, fragment) {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("%d problems start with %q, want 1", n, fragment)
+		}
+	}
+	if len(invalid.Problems) != len(want) {
+		t.Errorf("Problems = %q, want one per setting in %q", invalid.Problems, want)
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// validate returns a problem for every invalid field.
+func (c *Config) validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(validPort(c.ServerPort), "SERVER_PORT: %q is not a port number", c.ServerPort)
+	check(c.GRPCPort == "" || validPort(c.GRPCPort), "GRPC_PORT: %q is not a port number, leave it empty to disable gRPC", c.GRPCPort)
+	check(c.GRPCPort == "" || c.GRPCPort != c.ServerPort, "GRPC_PORT: must differ from SERVER_PORT")
+	check(c.TrashRetentionDays >= 1, "TRASH_RETENTION_DAYS: must be at least 1")
+	check(c.ShutdownDrainSecs >= 0, "SHUTDOWN_DRAIN_SECONDS: must not be negative")
+	check(oneOf(c.LogFormat, "", "text", "json"), "LOG_FORMAT: %q is not text or json", c.LogFormat)
+	check(oneOf(c.LogLevel, "debug", "info", "warn", "error"), "LOG_LEVEL: %q is not debug, info, warn or error", c.LogLevel)
+
+	if c.RedisURL != "" {
+		u, err := url.Parse(c.RedisURL)
+		check(err == nil && (u.Scheme == "redis" || u.Scheme == "rediss") && u.Host != "", "REDIS_URL: expected redis://host:port")
+	}
+
+	if c.CountryHeader != "" {
+		check(!strings.ContainsAny(c.CountryHeader, " :\t"), "GEO_COUNTRY_HEADER: %q is not a header name", c.CountryHeader)
+	}
+
+	check(c.Database.Host != "", "DATABASE_HOST: required")
+	check(validPort(c.Database.Port), "DATABASE_PORT: %q is not a port number", c.Database.Port)
+	check(c.Database.User != "", "DATABASE_USER: required")
+	check(c.Database.Name != "", "DATABASE_NAME: required")
+
+	check(oneOf(c.Tracing.Exporter, "", "none", "otlp", "stdout"), "TRACING_EXPORTER: %q is not none, otlp or stdout", c.Tracing.Exporter)
+	check(!strings.EqualFold(c.Tracing.Exporter, "otlp") || c.Tracing.Endpoint != "", "TRACING_OTLP_ENDPOINT: required by the otlp exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO: must be between 0 and 1")
+
+	return problems
+}
+
+func validPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
+
+func oneOf(value string, allowed ...string) bool {
+	return slices.Contains(allowed, strings.ToLower(value))
+}
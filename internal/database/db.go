@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/J0es1ick/shortli/internal/config"
@@ -9,7 +10,8 @@ import (
 )
 
 type Database struct {
-	DB *sqlx.DB
+	DB  *sqlx.DB
+	dsn string
 }
 
 func DBInit(cfg *config.Config) (*Database, error) {
@@ -21,9 +23,12 @@ func DBInit(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("can't connect to pg instance, %v", err)
 	}
 
-	return &Database{DB: conn}, nil
+	return &Database{DB: conn, dsn: connString}, nil
 }
 
 func (d *Database) Close() error {
 	return d.DB.Close()
-}
\ No newline at end of file
+}
+func (d *Database) Ping(ctx context.Context) error {
+	return d.DB.PingContext(ctx)
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// newMigrate opens a dedicated connection for golang-migrate so that closing
+// it never tears down the application pool.
+func (d *Database) newMigrate() (*migrate.Migrate, error) {
+	source, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("can't open migrations, %v", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, d.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("can't create migrate instance, %v", err)
+	}
+
+	return m, nil
+}
+
+func (d *Database) Migrate() error {
+	m, err := d.newMigrate()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migration failed, %v", err)
+	}
+
+	return nil
+}
+
+
+// LatestMigration returns the highest migration version embedded in the binary.
+func LatestMigration() (uint, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("can't read migrations, %v", err)
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
+
+// MigrationVersion returns the applied schema version. It returns
+// sql.ErrNoRows if no migration was applied yet.
+func (d *Database) MigrationVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	err = d.DB.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("can't read migration version, %v", err)
+	}
+	return version, dirty, err
+}
+
+// CheckMigrations reports an error unless the schema is clean and at least at
+// the version this binary was built with.
+func (d *Database) CheckMigrations(ctx context.Context) error {
+	latest, err := LatestMigration()
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := d.MigrationVersion(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no migrations applied, expected version %d", latest)
+		}
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < latest {
+		return fmt.Errorf("schema at version %d, expected %d", version, latest)
+	}
+
+	return nil
+}
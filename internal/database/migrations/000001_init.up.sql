@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS url_info (
+    url_id       SERIAL PRIMARY KEY,
+    original_url TEXT        NOT NULL,
+    short_code   VARCHAR(32) NOT NULL UNIQUE,
+    user_id      INTEGER     NOT NULL DEFAULT 0,
+    click_count  INTEGER     NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_url_info_original_url ON url_info (original_url);
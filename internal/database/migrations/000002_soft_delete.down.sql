@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS url_tombstones;
+
+ALTER TABLE url_info DROP COLUMN IF EXISTS deleted_at;
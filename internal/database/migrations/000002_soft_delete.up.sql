@@ -0,0 +1,8 @@
+ALTER TABLE url_info ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_url_info_deleted_at ON url_info (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE url_tombstones (
+    short_code VARCHAR(32) PRIMARY KEY,
+    purged_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+CREATE TABLE click_events (
+    click_id   BIGSERIAL PRIMARY KEY,
+    url_id     INTEGER     NOT NULL REFERENCES url_info (url_id) ON DELETE CASCADE,
+    short_code VARCHAR(32) NOT NULL,
+    referrer   TEXT        NOT NULL DEFAULT '',
+    user_agent TEXT        NOT NULL DEFAULT '',
+    ip_address TEXT        NOT NULL DEFAULT '',
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_click_events_url_id ON click_events (url_id, clicked_at);
+CREATE INDEX idx_click_events_clicked_at ON click_events (clicked_at);
@@ -0,0 +1,3 @@
+ALTER TABLE url_info ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_url_info_tags ON url_info USING GIN (tags);
@@ -0,0 +1 @@
+ALTER TABLE url_info DROP COLUMN IF EXISTS imported_at;
@@ -0,0 +1 @@
+ALTER TABLE url_info ADD COLUMN imported_at TIMESTAMPTZ;
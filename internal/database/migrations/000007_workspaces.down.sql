@@ -0,0 +1,8 @@
+ALTER TABLE webhooks DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE url_info DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS users;
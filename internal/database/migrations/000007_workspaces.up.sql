@@ -0,0 +1,70 @@
+CREATE TABLE users (
+    user_id    SERIAL PRIMARY KEY,
+    email      TEXT        NOT NULL UNIQUE,
+    name       TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE api_keys (
+    api_key_id   SERIAL PRIMARY KEY,
+    user_id      INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name         TEXT        NOT NULL DEFAULT '',
+    prefix       TEXT        NOT NULL,
+    key_hash     TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE TABLE workspaces (
+    workspace_id SERIAL PRIMARY KEY,
+    name         TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE workspace_members (
+    workspace_id INTEGER     NOT NULL REFERENCES workspaces (workspace_id) ON DELETE CASCADE,
+    user_id      INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    role         TEXT        NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members (user_id);
+
+CREATE TABLE workspace_invitations (
+    invitation_id SERIAL PRIMARY KEY,
+    workspace_id  INTEGER     NOT NULL REFERENCES workspaces (workspace_id) ON DELETE CASCADE,
+    email         TEXT        NOT NULL,
+    role          TEXT        NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    token_hash    TEXT        NOT NULL UNIQUE,
+    invited_by    INTEGER     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    accepted_at   TIMESTAMPTZ
+);
+
+-- Links and webhooks created before workspaces existed move into a shared
+-- default workspace so that they stay reachable. Their user_id was always 0
+-- and no users exist yet, so the workspace starts without members and no
+-- one can see its links until an owner is added. The server logs a warning
+-- at startup while any workspace has no owner; to recover, create its owner
+-- with
+--
+--   shortliService user create -email <email> -workspace <workspace_id> -role owner
+--
+-- or make an existing user its owner:
+--
+--   INSERT INTO workspace_members (workspace_id, user_id, role)
+--   VALUES (<workspace_id>, <user_id>, 'owner');
+INSERT INTO workspaces (name) VALUES ('Default');
+
+ALTER TABLE url_info ADD COLUMN workspace_id INTEGER REFERENCES workspaces (workspace_id);
+UPDATE url_info SET workspace_id = (SELECT MIN(workspace_id) FROM workspaces);
+ALTER TABLE url_info ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX idx_url_info_workspace_id ON url_info (workspace_id);
+
+ALTER TABLE webhooks ADD COLUMN workspace_id INTEGER REFERENCES workspaces (workspace_id) ON DELETE CASCADE;
+UPDATE webhooks SET workspace_id = (SELECT MIN(workspace_id) FROM workspaces);
+ALTER TABLE webhooks ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX idx_webhooks_workspace_id ON webhooks (workspace_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_url_info_canonical_hash;
+ALTER TABLE url_info DROP COLUMN IF EXISTS canonical_hash;
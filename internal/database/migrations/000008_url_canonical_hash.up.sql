@@ -0,0 +1,5 @@
+ALTER TABLE url_info ADD COLUMN canonical_hash CHAR(64);
+
+-- Links created before canonicalization keep a NULL hash and are matched by
+-- their exact original_url instead.
+CREATE INDEX idx_url_info_canonical_hash ON url_info (workspace_id, canonical_hash) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_url_info_canonical_hash;
+
+CREATE INDEX idx_url_info_owner_canonical_hash ON url_info (workspace_id, user_id, canonical_hash) WHERE deleted_at IS NULL;
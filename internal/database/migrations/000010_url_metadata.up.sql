@@ -0,0 +1,16 @@
+CREATE TABLE url_metadata (
+    url_id INT PRIMARY KEY REFERENCES url_info(url_id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ok', 'failed')),
+    title TEXT,
+    description TEXT,
+    site_name TEXT,
+    image_url TEXT,
+    og_type TEXT,
+    twitter_card TEXT,
+    favicon_url TEXT,
+    last_error TEXT,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    fetched_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_url_metadata_pending ON url_metadata (requested_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+ALTER TABLE url_info DROP COLUMN IF EXISTS og_image_url;
+ALTER TABLE url_info DROP COLUMN IF EXISTS og_description;
+ALTER TABLE url_info DROP COLUMN IF EXISTS og_title;
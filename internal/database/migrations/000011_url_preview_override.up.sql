@@ -0,0 +1,3 @@
+ALTER TABLE url_info ADD COLUMN og_title TEXT;
+ALTER TABLE url_info ADD COLUMN og_description TEXT;
+ALTER TABLE url_info ADD COLUMN og_image_url TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE click_events DROP COLUMN IF EXISTS classification;
+ALTER TABLE url_info DROP COLUMN IF EXISTS bot_click_count;
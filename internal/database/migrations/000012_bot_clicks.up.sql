@@ -0,0 +1,2 @@
+ALTER TABLE url_info ADD COLUMN bot_click_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE click_events ADD COLUMN classification VARCHAR(16) NOT NULL DEFAULT 'human';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS url_visitor_sketches;
+DROP TABLE IF EXISTS visitor_salts;
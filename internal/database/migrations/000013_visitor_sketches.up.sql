@@ -0,0 +1,14 @@
+CREATE TABLE visitor_salts (
+    day        DATE PRIMARY KEY,
+    salt       BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE url_visitor_sketches (
+    url_id     INTEGER     NOT NULL REFERENCES url_info (url_id) ON DELETE CASCADE,
+    day        DATE        NOT NULL,
+    sketch     BYTEA       NOT NULL,
+    estimate   BIGINT      NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (url_id, day)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS rollup_state;
+DROP TABLE IF EXISTS click_rollups_daily;
+DROP TABLE IF EXISTS click_rollups_hourly;
@@ -0,0 +1,24 @@
+CREATE TABLE click_rollups_hourly (
+    url_id       INTEGER     NOT NULL REFERENCES url_info (url_id) ON DELETE CASCADE,
+    bucket       TIMESTAMPTZ NOT NULL,
+    human_clicks BIGINT      NOT NULL DEFAULT 0,
+    bot_clicks   BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (url_id, bucket)
+);
+
+CREATE TABLE click_rollups_daily (
+    url_id       INTEGER NOT NULL REFERENCES url_info (url_id) ON DELETE CASCADE,
+    day          DATE    NOT NULL,
+    human_clicks BIGINT  NOT NULL DEFAULT 0,
+    bot_clicks   BIGINT  NOT NULL DEFAULT 0,
+    PRIMARY KEY (url_id, day)
+);
+
+-- rollup_state records the last click event folded into the rollups.
+CREATE TABLE rollup_state (
+    name          TEXT PRIMARY KEY,
+    last_click_id BIGINT      NOT NULL DEFAULT 0,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO rollup_state (name) VALUES ('clicks');
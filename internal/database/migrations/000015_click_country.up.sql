@@ -0,0 +1 @@
+ALTER TABLE click_events ADD COLUMN country CHAR(2) NOT NULL DEFAULT '';
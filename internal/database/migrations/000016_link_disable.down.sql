@@ -0,0 +1,2 @@
+ALTER TABLE url_info DROP COLUMN IF EXISTS disabled_reason;
+ALTER TABLE url_info DROP COLUMN IF EXISTS disabled_at;
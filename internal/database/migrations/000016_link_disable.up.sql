@@ -0,0 +1,2 @@
+ALTER TABLE url_info ADD COLUMN disabled_at TIMESTAMPTZ;
+ALTER TABLE url_info ADD COLUMN disabled_reason TEXT;
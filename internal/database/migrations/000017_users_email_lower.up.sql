@@ -0,0 +1,4 @@
+-- Emails are looked up case-insensitively, so they must also be unique
+-- case-insensitively. Fails if accounts differing only in case already
+-- exist; merge or rename them first.
+CREATE UNIQUE INDEX idx_users_email_lower ON users (LOWER(email));
@@ -0,0 +1,3 @@
+-- Workspace analytics rank referrers and countries over the human clicks of
+-- a period, which the rollups do not break down.
+CREATE INDEX idx_click_events_human ON click_events (url_id, clicked_at) WHERE classification = 'human';
@@ -0,0 +1,3 @@
+ALTER TABLE click_events
+    ALTER COLUMN country TYPE CHAR(2),
+    ALTER COLUMN country SET DEFAULT '';
@@ -0,0 +1,5 @@
+-- CHAR(2) blank-pads unknown countries to '  ', which leaks into exports
+-- and compares unequal to '' as text. Existing values are trimmed.
+ALTER TABLE click_events
+    ALTER COLUMN country TYPE VARCHAR(2) USING rtrim(country),
+    ALTER COLUMN country SET DEFAULT '';
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type ClickEvent struct {
+	ID        int64     `db:"click_id" json:"click_id"`
+	URLID     int       `db:"url_id" json:"url_id"`
+	ShortCode string    `db:"short_code" json:"short_code"`
+	Referrer  string    `db:"referrer" json:"referrer"`
+	UserAgent string    `db:"user_agent" json:"user_agent"`
+	IPAddress string    `db:"ip_address" json:"ip_address"`
+	ClickedAt time.Time `db:"clicked_at" json:"clicked_at"`
+	// Classification is "human", "bot" or "prefetch"; only human clicks
+	// count towards a link's click_count.
+	Classification string `db:"classification" json:"classification"`
+	// Country is the ISO 3166-1 alpha-2 code reported by the edge proxy,
+	// or empty when unknown.
+	Country string `db:"country" json:"country"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	MetadataStatusPending = "pending"
+	MetadataStatusOK      = "ok"
+	MetadataStatusFailed  = "failed"
+)
+
+// LinkMetadata describes the destination page of a link, as extracted from
+// its HTML by the metadata enricher.
+type LinkMetadata struct {
+	URLID       int        `db:"url_id" json:"-"`
+	Status      string     `db:"status" json:"status"`
+	Title       *string    `db:"title" json:"title,omitempty"`
+	Description *string    `db:"description" json:"description,omitempty"`
+	SiteName    *string    `db:"site_name" json:"site_name,omitempty"`
+	ImageURL    *string    `db:"image_url" json:"image_url,omitempty"`
+	OGType      *string    `db:"og_type" json:"og_type,omitempty"`
+	TwitterCard *string    `db:"twitter_card" json:"twitter_card,omitempty"`
+	FaviconURL  *string    `db:"favicon_url" json:"favicon_url,omitempty"`
+	LastError   *string    `db:"last_error" json:"last_error,omitempty"`
+	RequestedAt time.Time  `db:"requested_at" json:"requested_at"`
+	FetchedAt   *time.Time `db:"fetched_at" json:"fetched_at,omitempty"`
+}
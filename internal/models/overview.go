@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type OverviewTotals struct {
+	Links        int64 `db:"links" json:"links"`
+	Clicks       int64 `db:"clicks" json:"clicks"`
+	BotClicks    int64 `db:"bot_clicks" json:"bot_clicks"`
+	ClicksToday  int64 `db:"clicks_today" json:"clicks_today"`
+	ClicksWeek   int64 `db:"clicks_week" json:"clicks_week"`
+	ClicksPeriod int64 `db:"clicks_period" json:"clicks_period"`
+}
+
+type TopLink struct {
+	ShortCode   string `db:"short_code" json:"short_code"`
+	OriginalURL string `db:"original_url" json:"original_url"`
+	Clicks      int64  `db:"clicks" json:"clicks"`
+}
+
+// TopEntry is a referrer domain or country with its click count.
+type TopEntry struct {
+	Key    string `db:"key" json:"key"`
+	Clicks int64  `db:"clicks" json:"clicks"`
+}
+
+type DailyCount struct {
+	Day   time.Time `db:"day" json:"day"`
+	Count int64     `db:"count" json:"count"`
+}
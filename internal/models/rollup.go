@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// ClickBucket holds the clicks of a link in one time-series bucket.
+type ClickBucket struct {
+	Bucket         time.Time `db:"bucket" json:"bucket"`
+	Clicks         int64     `db:"human_clicks" json:"clicks"`
+	BotClicks      int64     `db:"bot_clicks" json:"bot_clicks"`
+	UniqueVisitors *int64    `db:"-" json:"unique_visitors,omitempty"`
+}
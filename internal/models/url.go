@@ -1,12 +1,35 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type URL struct {
 	ID           int       `db:"url_id" json:"url_id,omitempty"`
 	OriginalURL  string    `db:"original_url" json:"original_url,omitempty"`
 	ShortCode    string    `db:"short_code" json:"short_code,omitempty"`
 	UserId 		 int 	   `db:"user_id" json:"user_id,omitempty"`
+	WorkspaceID  int       `db:"workspace_id" json:"workspace_id,omitempty"`
 	ClickCount   int       `db:"click_count" json:"click_count,omitempty"`
+	BotClickCount int      `db:"bot_click_count" json:"bot_click_count,omitempty"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at,omitempty"`
+	DeletedAt    *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// DisabledAt is set when an operator takes the link down; disabled
+	// links no longer redirect.
+	DisabledAt     *time.Time `db:"disabled_at" json:"disabled_at,omitempty"`
+	DisabledReason *string    `db:"disabled_reason" json:"disabled_reason,omitempty"`
+	Tags         pq.StringArray `db:"tags" json:"tags,omitempty"`
+	ImportedAt   *time.Time `db:"imported_at" json:"imported_at,omitempty"`
+	CanonicalHash string    `db:"canonical_hash" json:"-"`
+	OGTitle       *string   `db:"og_title" json:"og_title,omitempty"`
+	OGDescription *string   `db:"og_description" json:"og_description,omitempty"`
+	OGImageURL    *string   `db:"og_image_url" json:"og_image_url,omitempty"`
+	Metadata     *LinkMetadata `db:"-" json:"metadata,omitempty"`
+}
+
+// HasPreview reports whether the link overrides its social preview.
+func (u *URL) HasPreview() bool {
+	return u.OGTitle != nil || u.OGDescription != nil || u.OGImageURL != nil
 }
\ No newline at end of file
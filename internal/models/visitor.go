@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// VisitorSketch is the HyperLogLog sketch of the visitors of a link on one
+// UTC day. Estimate caches the sketch's own estimate.
+type VisitorSketch struct {
+	URLID    int       `db:"url_id" json:"url_id"`
+	Day      time.Time `db:"day" json:"day"`
+	Sketch   []byte    `db:"sketch" json:"-"`
+	Estimate int64     `db:"estimate" json:"unique_visitors"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	EventLinkCreated  = "link.created"
+	EventLinkUpdated  = "link.updated"
+	EventLinkDeleted  = "link.deleted"
+	EventLinkRestored = "link.restored"
+	EventLinkExpired  = "link.expired"
+	EventLinkClicked  = "link.clicked"
+	EventLinkDisabled = "link.disabled"
+	EventLinkEnabled  = "link.enabled"
+)
+
+var WebhookEvents = []string{
+	EventLinkCreated,
+	EventLinkUpdated,
+	EventLinkDeleted,
+	EventLinkRestored,
+	EventLinkExpired,
+	EventLinkClicked,
+	EventLinkDisabled,
+	EventLinkEnabled,
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type Webhook struct {
+	ID          int            `db:"webhook_id" json:"webhook_id"`
+	UserId      int            `db:"user_id" json:"user_id"`
+	WorkspaceID int            `db:"workspace_id" json:"workspace_id"`
+	TargetURL   string         `db:"target_url" json:"target_url"`
+	Secret      string         `db:"secret" json:"-"`
+	Events      pq.StringArray `db:"events" json:"events"`
+	Active      bool           `db:"active" json:"active"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `db:"delivery_id" json:"delivery_id"`
+	WebhookID      int        `db:"webhook_id" json:"webhook_id"`
+	Event          string     `db:"event" json:"event"`
+	Payload        RawJSON    `db:"payload" json:"payload"`
+	Status         string     `db:"status" json:"status"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	ResponseStatus *int       `db:"response_status" json:"response_status,omitempty"`
+	LastError      *string    `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+// PendingWebhookDelivery is a claimed delivery together with the target it
+// has to be sent to.
+type PendingWebhookDelivery struct {
+	WebhookDelivery
+	TargetURL string `db:"target_url"`
+	Secret    string `db:"secret"`
+}
+
+// RawJSON holds a document loaded from a jsonb column and is emitted verbatim
+// when marshalled instead of as a quoted string.
+type RawJSON string
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
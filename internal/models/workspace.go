@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether role grants at least the permissions of
+// required. Owners can do everything editors can, editors everything viewers
+// can.
+func RoleAllows(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required] && roleRanks[required] > 0
+}
+
+type Workspace struct {
+	ID        int       `db:"workspace_id" json:"workspace_id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Role      string    `db:"role" json:"role,omitempty"`
+}
+
+type Membership struct {
+	WorkspaceID int       `db:"workspace_id" json:"workspace_id"`
+	UserId      int       `db:"user_id" json:"user_id"`
+	Email       string    `db:"email" json:"email,omitempty"`
+	Name        string    `db:"name" json:"name,omitempty"`
+	Role        string    `db:"role" json:"role"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+type Invitation struct {
+	ID          int        `db:"invitation_id" json:"invitation_id"`
+	WorkspaceID int        `db:"workspace_id" json:"workspace_id"`
+	Email       string     `db:"email" json:"email"`
+	Role        string     `db:"role" json:"role"`
+	TokenHash   string     `db:"token_hash" json:"-"`
+	InvitedBy   int        `db:"invited_by" json:"invited_by"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time  `db:"expires_at" json:"expires_at"`
+	AcceptedAt  *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+}
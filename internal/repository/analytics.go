@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// AnalyticsRepository answers workspace-wide questions. Click counts come
+// from the hourly rollups plus the click events not rolled up yet, and only
+// include human clicks on links that are not in the trash.
+type AnalyticsRepository struct {
+	db *sqlx.DB
+}
+
+func NewAnalyticsRepository(db *sqlx.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		db: db,
+	}
+}
+
+// workspaceClicks is a CTE of (url_id, ts, n) rows for the human clicks on
+// active links of workspace $1 since $2, which must be hour-aligned.
+const workspaceClicks = `
+	workspace_clicks AS (
+		SELECT h.url_id, h.bucket AS ts, h.human_clicks AS n
+		FROM click_rollups_hourly h
+		JOIN url_info u ON u.url_id = h.url_id
+		WHERE u.workspace_id = $1 AND u.deleted_at IS NULL AND h.bucket >= $2
+		UNION ALL
+		SELECT c.url_id, c.clicked_at, 1
+		FROM click_events c
+		JOIN url_info u ON u.url_id = c.url_id
+		WHERE u.workspace_id = $1 AND u.deleted_at IS NULL AND c.clicked_at >= $2
+			AND c.classification = 'human'
+			AND c.click_id > (SELECT last_click_id FROM rollup_state WHERE name = 'clicks')
+	)
+`
+
+// Totals counts the links and clicks of workspaceID, and the clicks since
+// today, week and period.
+func (r *AnalyticsRepository) Totals(ctx context.Context, workspaceID int, today, week, period time.Time) (*models.OverviewTotals, error) {
+	since := today
+	for _, t := range []time.Time{week, period} {
+		if t.Before(since) {
+			since = t
+		}
+	}
+
+	query := `
+		WITH ` + workspaceClicks + `,
+		links AS (
+			SELECT
+				COUNT(*) AS links,
+				COALESCE(SUM(click_count), 0) AS clicks,
+				COALESCE(SUM(bot_click_count), 0) AS bot_clicks
+			FROM url_info
+			WHERE workspace_id = $1 AND deleted_at IS NULL
+		)
+		SELECT
+			links.links,
+			links.clicks,
+			links.bot_clicks,
+			COALESCE((SELECT SUM(n) FROM workspace_clicks WHERE ts >= $3), 0) AS clicks_today,
+			COALESCE((SELECT SUM(n) FROM workspace_clicks WHERE ts >= $4), 0) AS clicks_week,
+			COALESCE((SELECT SUM(n) FROM workspace_clicks WHERE ts >= $5), 0) AS clicks_period
+		FROM links
+	`
+
+	totals := &models.OverviewTotals{}
+	if err := r.db.GetContext(ctx, totals, query, workspaceID, since, today, week, period); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return totals, nil
+}
+
+// TopLinks returns the limit links of workspaceID with the most clicks since.
+func (r *AnalyticsRepository) TopLinks(ctx context.Context, workspaceID int, since time.Time, limit int) ([]models.TopLink, error) {
+	query := `
+		WITH ` + workspaceClicks + `
+		SELECT u.short_code, u.original_url, SUM(wc.n) AS clicks
+		FROM workspace_clicks wc
+		JOIN url_info u ON u.url_id = wc.url_id
+		GROUP BY u.url_id, u.short_code, u.original_url
+		ORDER BY clicks DESC, u.url_id
+		LIMIT $3
+	`
+
+	links := []models.TopLink{}
+	if err := r.db.SelectContext(ctx, &links, query, workspaceID, since, limit); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return links, nil
+}
+
+// TopReferrers returns the limit referrer domains with the most clicks
+// since, ignoring a leading "www.". Direct traffic is left out.
+func (r *AnalyticsRepository) TopReferrers(ctx context.Context, workspaceID int, since time.Time, limit int) ([]models.TopEntry, error) {
+	return r.topClickAttribute(ctx, `regexp_replace(lower(COALESCE(substring(c.referrer from '^[A-Za-z][A-Za-z0-9+.-]*://([^/?#:@]+)'), '')), '^www\.', '')`, workspaceID, since, limit)
+}
+
+// TopCountries returns the limit countries with the most clicks since.
+// Clicks from unknown countries are left out.
+func (r *AnalyticsRepository) TopCountries(ctx context.Context, workspaceID int, since time.Time, limit int) ([]models.TopEntry, error) {
+	return r.topClickAttribute(ctx, "c.country", workspaceID, since, limit)
+}
+
+// topClickAttribute ranks the non-empty values of expr over the human
+// clicks since. Rollups carry no per-click attributes, so it reads
+// click_events through idx_click_events_human.
+func (r *AnalyticsRepository) topClickAttribute(ctx context.Context, expr string, workspaceID int, since time.Time, limit int) ([]models.TopEntry, error) {
+	query := `
+		SELECT ` + expr + ` AS key, COUNT(*) AS clicks
+		FROM click_events c
+		JOIN url_info u ON u.url_id = c.url_id
+		WHERE u.workspace_id = $1 AND u.deleted_at IS NULL
+			AND c.clicked_at >= $2 AND c.classification = 'human'
+			AND ` + expr + ` <> ''
+		GROUP BY 1
+		ORDER BY clicks DESC, key
+		LIMIT $3
+	`
+
+	entries := []models.TopEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, workspaceID, since, limit); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return entries, nil
+}
+
+// NewLinksPerDay counts the links created in workspaceID per UTC day since,
+// including days without any.
+func (r *AnalyticsRepository) NewLinksPerDay(ctx context.Context, workspaceID int, since time.Time) ([]models.DailyCount, error) {
+	query := `
+		SELECT d.day::timestamp AT TIME ZONE 'UTC' AS day, COUNT(u.url_id) AS count
+		FROM generate_series(($2::timestamptz AT TIME ZONE 'UTC')::date, (NOW() AT TIME ZONE 'UTC')::date, interval '1 day') AS d(day)
+		LEFT JOIN url_info u
+			ON u.workspace_id = $1
+			AND u.created_at >= d.day::timestamp AT TIME ZONE 'UTC'
+			AND u.created_at < (d.day + interval '1 day')::timestamp AT TIME ZONE 'UTC'
+		GROUP BY d.day
+		ORDER BY d.day
+	`
+
+	counts := []models.DailyCount{}
+	if err := r.db.SelectContext(ctx, &counts, query, workspaceID, since); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return counts, nil
+}
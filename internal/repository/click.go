@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type ClickRepository struct {
+	db *sqlx.DB
+}
+
+func NewClickRepository(db *sqlx.DB) *ClickRepository {
+	return &ClickRepository{
+		db: db,
+	}
+}
+
+func (r *ClickRepository) SaveClick(click *models.ClickEvent) error {
+	query := `
+		INSERT INTO click_events
+			(url_id, short_code, referrer, user_agent, ip_address, clicked_at, classification, country)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7::text, ''), 'human'), $8)
+	`
+
+	_, err := r.db.Exec(
+		query,
+		click.URLID,
+		click.ShortCode,
+		click.Referrer,
+		click.UserAgent,
+		click.IPAddress,
+		click.ClickedAt,
+		click.Classification,
+		click.Country,
+	)
+	if err != nil {
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *ClickRepository) StreamClicks(ctx context.Context, filter ExportFilter, fn func(*models.ClickEvent) error) error {
+	where, args := filter.where("c.clicked_at", "u.tags")
+	query := `
+		SELECT
+			c.click_id,
+			c.url_id,
+			c.short_code,
+			c.referrer,
+			c.user_agent,
+			c.ip_address,
+			c.clicked_at,
+			c.classification,
+			c.country
+		FROM click_events c
+		JOIN url_info u ON u.url_id = c.url_id
+		` + where + `
+		ORDER BY c.click_id
+	`
+
+	return streamCursor(ctx, r.db, query, args, func(rows *sqlx.Rows) error {
+		var click models.ClickEvent
+		if err := rows.StructScan(&click); err != nil {
+			return fmt.Errorf("scan error: %v", err)
+		}
+		return fn(&click)
+	})
+}
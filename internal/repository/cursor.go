@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const cursorFetchSize = 500
+
+// ExportFilter narrows streamed exports. Zero values mean "no restriction".
+type ExportFilter struct {
+	WorkspaceID *int
+	UserID      *int
+	From        *time.Time
+	To          *time.Time
+	Tags        []string
+}
+
+// where renders the filter as a WHERE clause over the given timestamp column.
+// Tags match links that carry all of the requested tags.
+func (f ExportFilter) where(timeColumn, tagsColumn string) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.WorkspaceID != nil {
+		add("u.workspace_id = $%d", *f.WorkspaceID)
+	}
+	if f.UserID != nil {
+		add("u.user_id = $%d", *f.UserID)
+	}
+	if f.From != nil {
+		add(timeColumn+" >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add(timeColumn+" < $%d", *f.To)
+	}
+	if len(f.Tags) > 0 {
+		add(tagsColumn+" @> $%d::text[]", pq.StringArray(f.Tags))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// streamCursor runs query through a server-side cursor inside a read-only
+// transaction and calls fn for every row, fetching cursorFetchSize rows at a
+// time so that exports never hold the full result in memory.
+func streamCursor(ctx context.Context, db *sqlx.DB, query string, args []interface{}, fn func(*sqlx.Rows) error) error {
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("begin tx error: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE export_cursor NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("declare cursor error: %v", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM export_cursor", cursorFetchSize)
+	for {
+		rows, err := tx.QueryxContext(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("fetch error: %v", err)
+		}
+
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			if err := fn(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows error: %v", err)
+		}
+		if fetched < cursorFetchSize {
+			break
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type MetadataRepository struct {
+	db *sqlx.DB
+}
+
+func NewMetadataRepository(db *sqlx.DB) *MetadataRepository {
+	return &MetadataRepository{
+		db: db,
+	}
+}
+
+const metadataColumns = `url_id, status, title, description, site_name, image_url, og_type,
+	twitter_card, favicon_url, last_error, requested_at, fetched_at`
+
+// RequestFetch marks the metadata of urlID as pending. Previously fetched
+// values stay visible until the new fetch completes.
+func (r *MetadataRepository) RequestFetch(ctx context.Context, urlID int) error {
+	query := `
+		INSERT INTO url_metadata (url_id, status, requested_at)
+		VALUES ($1, 'pending', NOW())
+		ON CONFLICT (url_id) DO UPDATE SET status = 'pending', requested_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, urlID); err != nil {
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *MetadataRepository) SaveMetadata(ctx context.Context, metadata *models.LinkMetadata) error {
+	query := `
+		UPDATE url_metadata
+		SET status = $2, title = $3, description = $4, site_name = $5, image_url = $6,
+			og_type = $7, twitter_card = $8, favicon_url = $9, last_error = $10, fetched_at = NOW()
+		WHERE url_id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		metadata.URLID,
+		metadata.Status,
+		metadata.Title,
+		metadata.Description,
+		metadata.SiteName,
+		metadata.ImageURL,
+		metadata.OGType,
+		metadata.TwitterCard,
+		metadata.FaviconURL,
+		metadata.LastError,
+	)
+	if err != nil {
+		return fmt.Errorf("update value error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *MetadataRepository) FindMetadata(ctx context.Context, urlID int) (*models.LinkMetadata, error) {
+	metadata := &models.LinkMetadata{}
+	err := r.db.GetContext(ctx, metadata, "SELECT "+metadataColumns+" FROM url_metadata WHERE url_id = $1", urlID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("metadata not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return metadata, nil
+}
+
+// FindMetadataByURLs returns the metadata of the given links keyed by url_id.
+// Links without metadata are absent from the map.
+func (r *MetadataRepository) FindMetadataByURLs(ctx context.Context, urlIDs []int) (map[int]*models.LinkMetadata, error) {
+	list := []models.LinkMetadata{}
+	err := r.db.SelectContext(ctx, &list, "SELECT "+metadataColumns+" FROM url_metadata WHERE url_id = ANY($1)", pq.Array(urlIDs))
+	if err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	result := make(map[int]*models.LinkMetadata, len(list))
+	for i := range list {
+		result[list[i].URLID] = &list[i]
+	}
+
+	return result, nil
+}
+
+// FindPendingURLs returns links whose metadata has been pending for longer
+// than a fetch can take, which happens when a queued fetch was dropped or
+// the process restarted.
+func (r *MetadataRepository) FindPendingURLs(ctx context.Context, olderThanSeconds float64, limit int) ([]models.URL, error) {
+	query := `
+		SELECT u.url_id, u.original_url, u.short_code
+		FROM url_metadata m
+		JOIN url_info u ON u.url_id = m.url_id
+		WHERE m.status = 'pending' AND m.requested_at < NOW() - $1 * INTERVAL '1 second'
+			AND u.deleted_at IS NULL
+		ORDER BY m.requested_at
+		LIMIT $2
+	`
+
+	urls := []models.URL{}
+	if err := r.db.SelectContext(ctx, &urls, query, olderThanSeconds, limit); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return urls, nil
+}
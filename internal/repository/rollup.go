@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+)
+
+// RollupClicks folds up to batchSize click events recorded after the last
+// run into the hourly and daily rollups and returns how many were folded.
+// Events younger than settle are left for the next run, so that an insert
+// whose ID was assigned earlier but committed later is not skipped.
+func (r *ClickRepository) RollupClicks(ctx context.Context, batchSize int, settle time.Duration) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx error: %v", err)
+	}
+	defer tx.Rollback()
+
+	var lastID int64
+	err = tx.QueryRowContext(ctx, "SELECT last_click_id FROM rollup_state WHERE name = 'clicks' FOR UPDATE").Scan(&lastID)
+	if err != nil {
+		return 0, fmt.Errorf("select error: %v", err)
+	}
+
+	var upperID, count int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(click_id), $1), COUNT(*)
+		FROM (
+			SELECT click_id FROM click_events
+			WHERE click_id > $1 AND clicked_at < NOW() - make_interval(secs => $3)
+			ORDER BY click_id
+			LIMIT $2
+		) batch
+	`, lastID, batchSize, settle.Seconds()).Scan(&upperID, &count)
+	if err != nil {
+		return 0, fmt.Errorf("select error: %v", err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO click_rollups_hourly (url_id, bucket, human_clicks, bot_clicks)
+		SELECT
+			url_id,
+			date_trunc('hour', clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+			COUNT(*) FILTER (WHERE classification = 'human'),
+			COUNT(*) FILTER (WHERE classification <> 'human')
+		FROM click_events
+		WHERE click_id > $1 AND click_id <= $2
+		GROUP BY 1, 2
+		ON CONFLICT (url_id, bucket) DO UPDATE SET
+			human_clicks = click_rollups_hourly.human_clicks + EXCLUDED.human_clicks,
+			bot_clicks = click_rollups_hourly.bot_clicks + EXCLUDED.bot_clicks
+	`, lastID, upperID)
+	if err != nil {
+		return 0, fmt.Errorf("insert value error: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO click_rollups_daily (url_id, day, human_clicks, bot_clicks)
+		SELECT
+			url_id,
+			(clicked_at AT TIME ZONE 'UTC')::date,
+			COUNT(*) FILTER (WHERE classification = 'human'),
+			COUNT(*) FILTER (WHERE classification <> 'human')
+		FROM click_events
+		WHERE click_id > $1 AND click_id <= $2
+		GROUP BY 1, 2
+		ON CONFLICT (url_id, day) DO UPDATE SET
+			human_clicks = click_rollups_daily.human_clicks + EXCLUDED.human_clicks,
+			bot_clicks = click_rollups_daily.bot_clicks + EXCLUDED.bot_clicks
+	`, lastID, upperID)
+	if err != nil {
+		return 0, fmt.Errorf("insert value error: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE rollup_state SET last_click_id = $1, updated_at = NOW() WHERE name = 'clicks'", upperID)
+	if err != nil {
+		return 0, fmt.Errorf("update value error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit error: %v", err)
+	}
+
+	return count, nil
+}
+
+// ClickSeries returns the clicks of urlID in [from, to) grouped by unit
+// ("hour", "day", "week" or "month") in the time zone tz. Only non-empty
+// buckets are returned.
+//
+// Rollups are read from the daily table when useDaily is set, which is
+// only correct for UTC day-aligned ranges, and from the hourly table
+// otherwise. Events not rolled up yet are read from click_events so the
+// series is always current.
+func (r *ClickRepository) ClickSeries(ctx context.Context, urlID int, from, to time.Time, unit, tz string, useDaily bool) ([]models.ClickBucket, error) {
+	rollups := `
+		SELECT bucket AS ts, human_clicks, bot_clicks
+		FROM click_rollups_hourly
+		WHERE url_id = $1 AND bucket >= $2 AND bucket < $3
+	`
+	if useDaily {
+		rollups = `
+		SELECT day::timestamp AT TIME ZONE 'UTC' AS ts, human_clicks, bot_clicks
+		FROM click_rollups_daily
+		WHERE url_id = $1 AND day >= ($2::timestamptz AT TIME ZONE 'UTC')::date
+			AND day < ($3::timestamptz AT TIME ZONE 'UTC')::date
+		`
+	}
+
+	query := `
+		WITH source AS (
+			` + rollups + `
+			UNION ALL
+			SELECT
+				clicked_at,
+				(classification = 'human')::int,
+				(classification <> 'human')::int
+			FROM click_events
+			WHERE url_id = $1 AND clicked_at >= $2 AND clicked_at < $3
+				AND click_id > (SELECT last_click_id FROM rollup_state WHERE name = 'clicks')
+		)
+		SELECT
+			date_trunc($4::text, ts AT TIME ZONE $5::text) AT TIME ZONE $5::text AS bucket,
+			SUM(human_clicks)::bigint AS human_clicks,
+			SUM(bot_clicks)::bigint AS bot_clicks
+		FROM source
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	buckets := []models.ClickBucket{}
+	if err := r.db.SelectContext(ctx, &buckets, query, urlID, from, to, unit, tz); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return buckets, nil
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/J0es1ick/shortli/internal/models"
 	"github.com/jmoiron/sqlx"
@@ -20,27 +22,43 @@ func NewUrlRepository(db *sqlx.DB) *UrlRepository {
 	}
 }
 
-func (r *UrlRepository) SaveUrl(url *models.URL) (int64, error) {
+func (r *UrlRepository) SaveUrl(ctx context.Context, url *models.URL) (int64, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.SaveUrl")
+    defer span.End()
+
     query := `
         INSERT INTO url_info 
-            (original_url, short_code, user_id, click_count, created_at) 
-        VALUES ($1, $2, $3, $4, $5)
+            (original_url, short_code, user_id, click_count, created_at, tags, imported_at, workspace_id, canonical_hash,
+             og_title, og_description, og_image_url) 
+        SELECT $1::text, $2::varchar, $3::int, $4::int, $5::timestamptz, COALESCE($6::text[], '{}'), $7::timestamptz, $8::int, NULLIF($9::text, ''),
+            $10::text, $11::text, $12::text
+        WHERE NOT EXISTS (
+            SELECT 1 FROM url_tombstones WHERE short_code = $2::varchar
+        )
         RETURNING url_id
     `
     
     var id int64
-    err := r.db.QueryRow(
+    err := r.db.QueryRowContext(
+        ctx,
         query,
         url.OriginalURL,
         url.ShortCode,
         url.UserId,
         url.ClickCount,
         url.CreatedAt,
+        url.Tags,
+        url.ImportedAt,
+        url.WorkspaceID,
+        url.CanonicalHash,
+        url.OGTitle,
+        url.OGDescription,
+        url.OGImageURL,
     ).Scan(&id)
     
     if err != nil {
-        var pgErr *pq.Error
-        if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+        recordError(span, err)
+        if errors.Is(err, sql.ErrNoRows) || isUniqueViolation(err) {
             return 0, fmt.Errorf("url with this code already exists")
         }
         return 0, fmt.Errorf("insert value error: %v", err)
@@ -49,23 +67,37 @@ func (r *UrlRepository) SaveUrl(url *models.URL) (int64, error) {
     return id, nil
 }
 
-func (r *UrlRepository) FindAllUrl(limit, offset int) ([]models.URL, error) {
+// FindAllUrl returns a page of the active links of a workspace. When tags is
+// not empty only links carrying all of them are returned.
+func (r *UrlRepository) FindAllUrl(ctx context.Context, workspaceID int, tags []string, limit, offset int) ([]models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.FindAllUrl")
+    defer span.End()
+
     query := `
         SELECT 
             url_id, 
             original_url, 
             short_code, 
             user_id,
+            workspace_id,
             click_count, 
-            created_at 
+            bot_click_count,
+            created_at,
+            tags,
+            og_title,
+            og_description,
+            og_image_url
         FROM url_info
+        WHERE deleted_at IS NULL AND workspace_id = $3
+            AND ($4::text[] IS NULL OR tags @> $4::text[])
         LIMIT $1 OFFSET $2
     `
 
     urls := []models.URL{}
-    err := r.db.Select(&urls, query, limit, offset)
+    err := r.db.SelectContext(ctx, &urls, query, limit, offset, workspaceID, tagFilter(tags))
 
     if err != nil {
+        recordError(span, err)
         if err == sql.ErrNoRows {
             return nil, fmt.Errorf("url not found")
         }
@@ -79,40 +111,64 @@ func (r *UrlRepository) FindAllUrl(limit, offset int) ([]models.URL, error) {
     return urls, nil
 }
 
-func (r *UrlRepository) GetTotalUrls() (int, error) {
+func (r *UrlRepository) GetTotalUrls(ctx context.Context, workspaceID int, tags []string) (int, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.GetTotalUrls")
+    defer span.End()
+
     var count int
-    err := r.db.QueryRow("SELECT COUNT(*) FROM url_info").Scan(&count)
+    err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM url_info WHERE deleted_at IS NULL AND workspace_id = $1 AND ($2::text[] IS NULL OR tags @> $2::text[])", workspaceID, tagFilter(tags)).Scan(&count)
     if err != nil {
+        recordError(span, err)
         return 0, fmt.Errorf("count error: %w", err)
     }
 
     return count, nil
 }
 
-func (r *UrlRepository) FindUrlByCode(code string) (*models.URL, error) {
+func (r *UrlRepository) FindUrlByCode(ctx context.Context, code string) (*models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.FindUrlByCode")
+    defer span.End()
+
     query := `
         SELECT 
             url_id, 
             original_url, 
             short_code, 
             user_id,
+            workspace_id,
             click_count, 
-            created_at 
+            bot_click_count,
+            created_at,
+            tags,
+            og_title,
+            og_description,
+            og_image_url,
+            disabled_at,
+            disabled_reason
         FROM url_info 
-        WHERE short_code = $1
+        WHERE short_code = $1 AND deleted_at IS NULL
     `
     
     url := &models.URL{}
-    err := r.db.QueryRow(query, code).Scan(
+    err := r.db.QueryRowContext(ctx, query, code).Scan(
         &url.ID,
         &url.OriginalURL,
         &url.ShortCode,
         &url.UserId,
+        &url.WorkspaceID,
         &url.ClickCount,
+        &url.BotClickCount,
         &url.CreatedAt,
+        &url.Tags,
+        &url.OGTitle,
+        &url.OGDescription,
+        &url.OGImageURL,
+        &url.DisabledAt,
+        &url.DisabledReason,
     )
     
     if err != nil {
+        recordError(span, err)
         if err == sql.ErrNoRows {
             return nil, fmt.Errorf("url not found")
         }
@@ -122,30 +178,60 @@ func (r *UrlRepository) FindUrlByCode(code string) (*models.URL, error) {
     return url, nil
 }
 
-func (r *UrlRepository) FindUrlByOriginalUrl(originalUrl string) (*models.URL, error) {
+// FindWorkspaceUrlByCode is FindUrlByCode restricted to links owned by
+// workspaceID.
+func (r *UrlRepository) FindWorkspaceUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error) {
+    url, err := r.FindUrlByCode(ctx, code)
+    if err != nil {
+        return nil, err
+    }
+
+    if url.WorkspaceID != workspaceID {
+        return nil, fmt.Errorf("url not found")
+    }
+
+    return url, nil
+}
+
+// FindUrlByCanonicalHash finds an active link created by ownerID in
+// workspaceID that points to the same canonical URL, so that links of other
+// members are never handed out as duplicates. Links stored before canonical
+// hashes existed are matched by their exact originalUrl.
+func (r *UrlRepository) FindUrlByCanonicalHash(ctx context.Context, workspaceID, ownerID int, canonicalHash, originalUrl string) (*models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.FindUrlByCanonicalHash")
+    defer span.End()
+
     query := `
         SELECT 
             url_id, 
             original_url, 
             short_code, 
             user_id,
+            workspace_id,
             click_count, 
-            created_at 
+            created_at,
+            tags
         FROM url_info 
-        WHERE original_url = $1
+        WHERE workspace_id = $1 AND user_id = $4 AND deleted_at IS NULL
+            AND (canonical_hash = $2 OR (canonical_hash IS NULL AND original_url = $3))
+        ORDER BY canonical_hash IS NULL, url_id
+        LIMIT 1
     `
 
     url := &models.URL{}
-    err := r.db.QueryRow(query, originalUrl).Scan(
+    err := r.db.QueryRowContext(ctx, query, workspaceID, canonicalHash, originalUrl, ownerID).Scan(
         &url.ID,
         &url.OriginalURL,
         &url.ShortCode,
         &url.UserId,
+        &url.WorkspaceID,
         &url.ClickCount,
         &url.CreatedAt,
+        &url.Tags,
     )
 
     if err != nil {
+        recordError(span, err)
         if err == sql.ErrNoRows {
             return nil, fmt.Errorf("url not found")
         }
@@ -155,25 +241,36 @@ func (r *UrlRepository) FindUrlByOriginalUrl(originalUrl string) (*models.URL, e
     return url, nil
 }
 
-func (r *UrlRepository) UpdateUrlByCode(url *models.URL) error {
+func (r *UrlRepository) UpdateUrlByCode(ctx context.Context, url *models.URL) error {
+    ctx, span := startSpan(ctx, "UrlRepository.UpdateUrlByCode")
+    defer span.End()
+
     query := `
         UPDATE url_info 
         SET 
             original_url = $1, 
-            click_count = $2,
-            created_at = $3
-        WHERE short_code = $4
+            created_at = $2,
+            canonical_hash = COALESCE(NULLIF($4::text, ''), canonical_hash),
+            og_title = $5,
+            og_description = $6,
+            og_image_url = $7
+        WHERE short_code = $3 AND deleted_at IS NULL
     `
     
-    result, err := r.db.Exec(
+    result, err := r.db.ExecContext(
+        ctx,
         query,
         url.OriginalURL,
-        url.ClickCount,
         url.CreatedAt,
         url.ShortCode,
+        url.CanonicalHash,
+        url.OGTitle,
+        url.OGDescription,
+        url.OGImageURL,
     )
     
     if err != nil {
+        recordError(span, err)
         return fmt.Errorf("update value error: %v", err)
     }
     
@@ -189,51 +286,306 @@ func (r *UrlRepository) UpdateUrlByCode(url *models.URL) error {
     return nil
 }
 
-func (r *UrlRepository) DeleteUrlByCode(code string) error {
+// IncrementClicks counts a human click to urlID and returns the new click
+// count. The increment happens in the database so that concurrent clicks
+// are never lost.
+func (r *UrlRepository) IncrementClicks(ctx context.Context, urlID int) (int, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.IncrementClicks")
+    defer span.End()
+
+    query := `UPDATE url_info SET click_count = click_count + 1 WHERE url_id = $1 RETURNING click_count`
+
+    var clickCount int
+    if err := r.db.QueryRowContext(ctx, query, urlID).Scan(&clickCount); err != nil {
+        recordError(span, err)
+        if err == sql.ErrNoRows {
+            return 0, fmt.Errorf("url not found")
+        }
+        return 0, fmt.Errorf("update value error: %v", err)
+    }
+
+    return clickCount, nil
+}
+
+// IncrementBotClicks counts a bot or prefetch request to urlID separately
+// from human clicks.
+func (r *UrlRepository) IncrementBotClicks(ctx context.Context, urlID int) error {
+    ctx, span := startSpan(ctx, "UrlRepository.IncrementBotClicks")
+    defer span.End()
+
+    query := `UPDATE url_info SET bot_click_count = bot_click_count + 1 WHERE url_id = $1`
+
+    if _, err := r.db.ExecContext(ctx, query, urlID); err != nil {
+        recordError(span, err)
+        return fmt.Errorf("update value error: %v", err)
+    }
+
+    return nil
+}
+
+func (r *UrlRepository) IsCodeReserved(ctx context.Context, code string) (bool, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.IsCodeReserved")
+    defer span.End()
+
     query := `
-        DELETE FROM url_info 
-        WHERE short_code = $1
-        RETURNING url_id
+        SELECT EXISTS (SELECT 1 FROM url_info WHERE short_code = $1)
+            OR EXISTS (SELECT 1 FROM url_tombstones WHERE short_code = $1)
+    `
+
+    var reserved bool
+    if err := r.db.QueryRowContext(ctx, query, code).Scan(&reserved); err != nil {
+        recordError(span, err)
+        return false, fmt.Errorf("select error: %v", err)
+    }
+
+    return reserved, nil
+}
+
+func (r *UrlRepository) DeleteUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.DeleteUrlByCode")
+    defer span.End()
+
+    query := `
+        UPDATE url_info 
+        SET deleted_at = NOW()
+        WHERE short_code = $1 AND deleted_at IS NULL AND workspace_id = $2
+        RETURNING url_id, original_url, short_code, user_id, workspace_id, click_count, created_at, deleted_at, tags
     `
     
-    var deletedID int64
-    err := r.db.QueryRow(query, code).Scan(&deletedID)
+    url := &models.URL{}
+    err := r.db.GetContext(ctx, url, query, code, workspaceID)
     
     if err != nil {
+        recordError(span, err)
         if err == sql.ErrNoRows {
-            return fmt.Errorf("url with code '%s' not found", code)
+            return nil, fmt.Errorf("url with code '%s' not found", code)
         }
-        return fmt.Errorf("delete value error: %v", err)
+        return nil, fmt.Errorf("delete value error: %v", err)
     }
     
-    return nil
+    return url, nil
 }
 
-func (r *UrlRepository)DeleteOldUrls() (int64, error) {
+func (r *UrlRepository) RestoreUrlByCode(ctx context.Context, workspaceID int, code string) (*models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.RestoreUrlByCode")
+    defer span.End()
+
     query := `
-        DELETE FROM url_info 
-        WHERE created_at < NOW() - INTERVAL '1 month'
-        RETURNING url_id
+        UPDATE url_info 
+        SET deleted_at = NULL
+        WHERE short_code = $1 AND deleted_at IS NOT NULL AND workspace_id = $2
+        RETURNING url_id, original_url, short_code, user_id, workspace_id, click_count, created_at, tags
     `
 
-    rows, err := r.db.Query(query)
+    url := &models.URL{}
+    err := r.db.GetContext(ctx, url, query, code, workspaceID)
+
     if err != nil {
-        return 0, fmt.Errorf("delete old urls error: %v", err)
+        recordError(span, err)
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("deleted url with code '%s' not found", code)
+        }
+        return nil, fmt.Errorf("restore value error: %v", err)
     }
-    defer rows.Close()
-    
-    var count int64
-    for rows.Next() {
-        var id int64
-        if err := rows.Scan(&id); err != nil {
-            return 0, fmt.Errorf("scan deleted id error: %v", err)
+
+    return url, nil
+}
+
+// SetUrlDisabled disables or re-enables the link behind code in any
+// workspace. The reason is cleared when the link is enabled.
+func (r *UrlRepository) SetUrlDisabled(ctx context.Context, code string, disabled bool, reason string) (*models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.SetUrlDisabled")
+    defer span.End()
+
+    query := `
+        UPDATE url_info 
+        SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, NOW()) END,
+            disabled_reason = CASE WHEN $2 THEN NULLIF($3, '') END
+        WHERE short_code = $1
+        RETURNING url_id, original_url, short_code, user_id, workspace_id, click_count, created_at, deleted_at, tags, disabled_at, disabled_reason
+    `
+
+    url := &models.URL{}
+    err := r.db.GetContext(ctx, url, query, code, disabled, reason)
+
+    if err != nil {
+        recordError(span, err)
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("url with code '%s' not found", code)
         }
-        count++
+        return nil, fmt.Errorf("update value error: %v", err)
     }
-    
-    if err := rows.Err(); err != nil {
-        return 0, fmt.Errorf("rows error: %v", err)
+
+    return url, nil
+}
+
+func (r *UrlRepository) FindDeletedUrls(ctx context.Context, workspaceID, limit, offset int) ([]models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.FindDeletedUrls")
+    defer span.End()
+
+    query := `
+        SELECT 
+            url_id, 
+            original_url, 
+            short_code, 
+            user_id,
+            workspace_id,
+            click_count, 
+            created_at,
+            deleted_at,
+            tags
+        FROM url_info
+        WHERE deleted_at IS NOT NULL AND workspace_id = $3
+        ORDER BY deleted_at DESC
+        LIMIT $1 OFFSET $2
+    `
+
+    urls := []models.URL{}
+    if err := r.db.SelectContext(ctx, &urls, query, limit, offset, workspaceID); err != nil {
+        recordError(span, err)
+        return nil, fmt.Errorf("select error: %v", err)
     }
-    
+
+    return urls, nil
+}
+
+func (r *UrlRepository) GetTotalDeletedUrls(ctx context.Context, workspaceID int) (int, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.GetTotalDeletedUrls")
+    defer span.End()
+
+    var count int
+    err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM url_info WHERE deleted_at IS NOT NULL AND workspace_id = $1", workspaceID).Scan(&count)
+    if err != nil {
+        recordError(span, err)
+        return 0, fmt.Errorf("count error: %w", err)
+    }
+
     return count, nil
-}
\ No newline at end of file
+}
+
+// archiveCondition selects the active links that cleanup moves to the
+// trash and purgeCondition the trash entries older than the retention,
+// bound as $1 in seconds. CountCleanupCandidates shares them so that a dry
+// run matches a real one.
+const (
+    archiveCondition = `COALESCE(imported_at, created_at) < NOW() - INTERVAL '1 month' AND deleted_at IS NULL`
+    purgeCondition   = `deleted_at < NOW() - $1 * INTERVAL '1 second'`
+)
+
+// ArchiveOldUrls moves links older than a month to the trash. They stay
+// restorable until PurgeDeletedUrls removes them. Imported links age from
+// their import rather than from their original creation date.
+func (r *UrlRepository) ArchiveOldUrls(ctx context.Context) ([]models.URL, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.ArchiveOldUrls")
+    defer span.End()
+
+    query := `
+        UPDATE url_info 
+        SET deleted_at = NOW()
+        WHERE ` + archiveCondition + `
+        RETURNING url_id, original_url, short_code, user_id, workspace_id, click_count, created_at, deleted_at, tags
+    `
+
+    urls := []models.URL{}
+    if err := r.db.SelectContext(ctx, &urls, query); err != nil {
+        recordError(span, err)
+        return nil, fmt.Errorf("archive old urls error: %v", err)
+    }
+
+    return urls, nil
+}
+
+// PurgeDeletedUrls permanently removes links that have been in the trash for
+// longer than retention and leaves a tombstone for each purged short code so
+// that it is never issued again.
+func (r *UrlRepository) PurgeDeletedUrls(ctx context.Context, retention time.Duration) (int64, error) {
+    ctx, span := startSpan(ctx, "UrlRepository.PurgeDeletedUrls")
+    defer span.End()
+
+    query := `
+        WITH purged AS (
+            DELETE FROM url_info 
+            WHERE ` + purgeCondition + `
+            RETURNING short_code
+        )
+        INSERT INTO url_tombstones (short_code)
+        SELECT short_code FROM purged
+        ON CONFLICT (short_code) DO NOTHING
+    `
+
+    result, err := r.db.ExecContext(ctx, query, retention.Seconds())
+    if err != nil {
+        recordError(span, err)
+        return 0, fmt.Errorf("purge deleted urls error: %v", err)
+    }
+
+    count, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("failed to get rows affected: %v", err)
+    }
+
+    return count, nil
+}
+
+// CountCleanupCandidates returns how many links ArchiveOldUrls and
+// PurgeDeletedUrls would affect right now.
+func (r *UrlRepository) CountCleanupCandidates(ctx context.Context, retention time.Duration) (archivable, purgeable int64, err error) {
+    ctx, span := startSpan(ctx, "UrlRepository.CountCleanupCandidates")
+    defer span.End()
+
+    query := `
+        SELECT
+            COUNT(*) FILTER (WHERE ` + archiveCondition + `),
+            COUNT(*) FILTER (WHERE ` + purgeCondition + `)
+        FROM url_info
+    `
+
+    if err := r.db.QueryRowContext(ctx, query, retention.Seconds()).Scan(&archivable, &purgeable); err != nil {
+        recordError(span, err)
+        return 0, 0, fmt.Errorf("count error: %v", err)
+    }
+
+    return archivable, purgeable, nil
+}
+
+func (r *UrlRepository) StreamUrls(ctx context.Context, filter ExportFilter, fn func(*models.URL) error) error {
+    ctx, span := startSpan(ctx, "UrlRepository.StreamUrls")
+    defer span.End()
+
+    where, args := filter.where("u.created_at", "u.tags")
+    query := `
+        SELECT 
+            u.url_id, 
+            u.original_url, 
+            u.short_code, 
+            u.user_id,
+            u.workspace_id,
+            u.click_count, 
+            u.created_at,
+            u.deleted_at,
+            u.tags
+        FROM url_info u
+        ` + where + `
+        ORDER BY u.url_id
+    `
+
+    err := streamCursor(ctx, r.db, query, args, func(rows *sqlx.Rows) error {
+        var url models.URL
+        if err := rows.StructScan(&url); err != nil {
+            return fmt.Errorf("scan error: %v", err)
+        }
+        return fn(&url)
+    })
+    recordError(span, err)
+
+    return err
+}
+
+// tagFilter binds tags as a text[] parameter, or NULL when there is no
+// filter.
+func tagFilter(tags []string) interface{} {
+    if len(tags) == 0 {
+        return nil
+    }
+    return pq.StringArray(tags)
+}
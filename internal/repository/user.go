@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (r *UserRepository) SaveUser(user *models.User) (int, error) {
+	query := `
+		INSERT INTO users (email, name, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING user_id
+	`
+
+	var id int
+	err := r.db.QueryRow(query, user.Email, user.Name, user.CreatedAt).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("user with this email already exists")
+		}
+		return 0, fmt.Errorf("insert value error: %v", err)
+	}
+
+	return id, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+// reported by the pgx driver.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (r *UserRepository) FindUserByID(id int) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Get(user, "SELECT user_id, email, name, created_at FROM users WHERE user_id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) FindUserByEmail(email string) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.Get(user, "SELECT user_id, email, name, created_at FROM users WHERE LOWER(email) = LOWER($1)", email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return user, nil
+}
+
+// FindUserByAPIKey resolves the owner of a non-revoked key and records that
+// the key has been used.
+func (r *UserRepository) FindUserByAPIKey(keyHash string) (*models.User, error) {
+	query := `
+		WITH used AS (
+			UPDATE api_keys
+			SET last_used_at = NOW()
+			WHERE key_hash = $1 AND revoked_at IS NULL
+			RETURNING user_id
+		)
+		SELECT u.user_id, u.email, u.name, u.created_at
+		FROM users u
+		JOIN used ON used.user_id = u.user_id
+	`
+
+	user := &models.User{}
+	if err := r.db.Get(user, query, keyHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) SaveAPIKey(key *models.APIKey) (int, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING api_key_id
+	`
+
+	var id int
+	err := r.db.QueryRow(query, key.UserId, key.Name, key.Prefix, key.KeyHash, key.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert value error: %v", err)
+	}
+
+	return id, nil
+}
+
+// Account is a user together with its first membership and API key, as
+// created by CreateAccount.
+type Account struct {
+	User *models.User
+	// WorkspaceID is the workspace to join with Role, or 0 to create a
+	// workspace named WorkspaceName that the user owns.
+	WorkspaceID   int
+	WorkspaceName string
+	Role          string
+	APIKey        *models.APIKey
+}
+
+// CreateAccount saves the user, its membership and its API key in one
+// transaction, so that a failed step never leaves a user behind whose email
+// blocks a retry. It fills in the generated ids.
+func (r *UserRepository) CreateAccount(account *Account) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin tx error: %v", err)
+	}
+	defer tx.Rollback()
+
+	user := account.User
+	err = tx.QueryRow(
+		"INSERT INTO users (email, name, created_at) VALUES ($1, $2, $3) RETURNING user_id",
+		user.Email, user.Name, user.CreatedAt,
+	).Scan(&user.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("user with this email already exists")
+		}
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	role := account.Role
+	if account.WorkspaceID == 0 {
+		role = models.RoleOwner
+		err = tx.QueryRow(
+			"INSERT INTO workspaces (name) VALUES ($1) RETURNING workspace_id",
+			account.WorkspaceName,
+		).Scan(&account.WorkspaceID)
+		if err != nil {
+			return fmt.Errorf("insert value error: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)",
+		account.WorkspaceID, user.ID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	key := account.APIKey
+	key.UserId = user.ID
+	err = tx.QueryRow(
+		"INSERT INTO api_keys (user_id, name, prefix, key_hash, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING api_key_id",
+		key.UserId, key.Name, key.Prefix, key.KeyHash, key.CreatedAt,
+	).Scan(&key.ID)
+	if err != nil {
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) FindAPIKeysByUser(userID int) ([]models.APIKey, error) {
+	query := `
+		SELECT api_key_id, user_id, name, prefix, key_hash, created_at, last_used_at, revoked_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY api_key_id
+	`
+
+	keys := []models.APIKey{}
+	if err := r.db.Select(&keys, query, userID); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return keys, nil
+}
+
+func (r *UserRepository) RevokeAPIKey(id int) error {
+	result, err := r.db.Exec("UPDATE api_keys SET revoked_at = NOW() WHERE api_key_id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("update value error: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("active api key %d not found", id)
+	}
+
+	return nil
+}
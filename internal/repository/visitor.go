@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type VisitorRepository struct {
+	db *sqlx.DB
+}
+
+func NewVisitorRepository(db *sqlx.DB) *VisitorRepository {
+	return &VisitorRepository{
+		db: db,
+	}
+}
+
+// SaltForDay returns the visitor hash salt of day, storing candidate if the
+// day has none yet so that every instance hashes visitors alike.
+func (r *VisitorRepository) SaltForDay(ctx context.Context, day time.Time, candidate []byte) ([]byte, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO visitor_salts (day, salt) VALUES ($1, $2)
+			ON CONFLICT (day) DO NOTHING
+			RETURNING salt
+		)
+		SELECT salt FROM inserted
+		UNION ALL
+		SELECT salt FROM visitor_salts WHERE day = $1
+		LIMIT 1
+	`
+
+	var salt []byte
+	if err := r.db.QueryRowContext(ctx, query, day, candidate).Scan(&salt); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return salt, nil
+}
+
+// DeleteSaltsBefore forgets the salts of days before day. Once a salt is
+// gone, the visitor hashes of that day can no longer be recomputed.
+func (r *VisitorRepository) DeleteSaltsBefore(ctx context.Context, day time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM visitor_salts WHERE day < $1", day)
+	if err != nil {
+		return 0, fmt.Errorf("delete error: %v", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// MergeSketch combines the stored sketch of urlID on day with pending. merge
+// receives the stored sketch, or nil when there is none, and returns the
+// sketch to store along with its estimate. The row is locked while merge
+// runs so that concurrent writers don't lose each other's updates.
+func (r *VisitorRepository) MergeSketch(ctx context.Context, urlID int, day time.Time, merge func(stored []byte) ([]byte, int64, error)) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx error: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO url_visitor_sketches (url_id, day, sketch) VALUES ($1, $2, '')
+		ON CONFLICT (url_id, day) DO NOTHING
+	`, urlID, day)
+	if err != nil {
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	var stored []byte
+	err = tx.QueryRowContext(ctx, `
+		SELECT sketch FROM url_visitor_sketches WHERE url_id = $1 AND day = $2 FOR UPDATE
+	`, urlID, day).Scan(&stored)
+	if err != nil {
+		return fmt.Errorf("select error: %v", err)
+	}
+	if len(stored) == 0 {
+		stored = nil
+	}
+
+	sketch, estimate, err := merge(stored)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE url_visitor_sketches SET sketch = $3, estimate = $4, updated_at = NOW()
+		WHERE url_id = $1 AND day = $2
+	`, urlID, day, sketch, estimate)
+	if err != nil {
+		return fmt.Errorf("update value error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit error: %v", err)
+	}
+
+	return nil
+}
+
+// FindSketches returns the daily sketches of urlID between from and to,
+// inclusive, ordered by day. A zero from or to leaves that end open.
+func (r *VisitorRepository) FindSketches(ctx context.Context, urlID int, from, to time.Time) ([]models.VisitorSketch, error) {
+	query := `
+		SELECT url_id, day, sketch, estimate
+		FROM url_visitor_sketches
+		WHERE url_id = $1
+			AND ($2::date IS NULL OR day >= $2)
+			AND ($3::date IS NULL OR day <= $3)
+			AND sketch <> ''
+		ORDER BY day
+	`
+
+	sketches := []models.VisitorSketch{}
+	if err := r.db.SelectContext(ctx, &sketches, query, urlID, nullDate(from), nullDate(to)); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return sketches, nil
+}
+
+func nullDate(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format("2006-01-02")
+}
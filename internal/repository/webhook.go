@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookRepository) SaveWebhook(webhook *models.Webhook) (int, error) {
+	query := `
+		INSERT INTO webhooks
+			(user_id, workspace_id, target_url, secret, events, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING webhook_id
+	`
+
+	var id int
+	err := r.db.QueryRow(
+		query,
+		webhook.UserId,
+		webhook.WorkspaceID,
+		webhook.TargetURL,
+		webhook.Secret,
+		webhook.Events,
+		webhook.Active,
+		webhook.CreatedAt,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, fmt.Errorf("insert value error: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *WebhookRepository) FindWebhooksByWorkspace(workspaceID int) ([]models.Webhook, error) {
+	query := `
+		SELECT
+			webhook_id,
+			user_id,
+			workspace_id,
+			target_url,
+			secret,
+			events,
+			active,
+			created_at
+		FROM webhooks
+		WHERE workspace_id = $1
+		ORDER BY webhook_id
+	`
+
+	webhooks := []models.Webhook{}
+	if err := r.db.Select(&webhooks, query, workspaceID); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) FindWebhookByID(id, workspaceID int) (*models.Webhook, error) {
+	query := `
+		SELECT
+			webhook_id,
+			user_id,
+			workspace_id,
+			target_url,
+			secret,
+			events,
+			active,
+			created_at
+		FROM webhooks
+		WHERE webhook_id = $1 AND workspace_id = $2
+	`
+
+	webhook := &models.Webhook{}
+	if err := r.db.Get(webhook, query, id, workspaceID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(id, workspaceID int) error {
+	result, err := r.db.Exec("DELETE FROM webhooks WHERE webhook_id = $1 AND workspace_id = $2", id, workspaceID)
+	if err != nil {
+		return fmt.Errorf("delete value error: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook %d not found", id)
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries queues one delivery of payload for every active webhook of
+// workspaceID that subscribes to event and returns how many were queued.
+func (r *WebhookRepository) EnqueueDeliveries(workspaceID int, event string, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT webhook_id, $2::text, $3::jsonb
+		FROM webhooks
+		WHERE workspace_id = $1 AND active AND $2 = ANY(events)
+	`
+
+	result, err := r.db.Exec(query, workspaceID, event, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("enqueue deliveries error: %v", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	return count, nil
+}
+
+// ClaimDueDeliveries picks up to limit pending deliveries whose next attempt
+// is due and pushes their next_attempt_at forward by lease, so that concurrent
+// workers skip them while they are being sent.
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.PendingWebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		FROM webhooks w
+		WHERE w.webhook_id = d.webhook_id AND d.delivery_id IN (
+			SELECT delivery_id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			d.delivery_id,
+			d.webhook_id,
+			d.event,
+			d.payload,
+			d.status,
+			d.attempts,
+			d.response_status,
+			d.last_error,
+			d.next_attempt_at,
+			d.last_attempt_at,
+			d.created_at,
+			w.target_url,
+			w.secret
+	`
+
+	deliveries := []models.PendingWebhookDelivery{}
+	if err := r.db.Select(&deliveries, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("claim deliveries error: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt. A nil nextAttempt
+// means no further retries: the delivery ends up delivered when attemptErr is
+// empty and dead-lettered otherwise.
+func (r *WebhookRepository) RecordAttempt(id int64, responseStatus int, attemptErr string, nextAttempt *time.Time) error {
+	status := models.DeliveryPending
+	if nextAttempt == nil {
+		status = models.DeliveryDelivered
+		if attemptErr != "" {
+			status = models.DeliveryDead
+		}
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET
+			status = $2,
+			attempts = attempts + 1,
+			response_status = NULLIF($3, 0),
+			last_error = NULLIF($4, ''),
+			next_attempt_at = COALESCE($5, next_attempt_at),
+			last_attempt_at = NOW()
+		WHERE delivery_id = $1
+	`
+
+	if _, err := r.db.Exec(query, id, status, responseStatus, attemptErr, nextAttempt); err != nil {
+		return fmt.Errorf("update value error: %v", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) FindDeliveries(webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT
+			delivery_id,
+			webhook_id,
+			event,
+			payload,
+			status,
+			attempts,
+			response_status,
+			last_error,
+			next_attempt_at,
+			last_attempt_at,
+			created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	deliveries := []models.WebhookDelivery{}
+	if err := r.db.Select(&deliveries, query, webhookID, status, limit); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// RequeueDelivery moves a dead-lettered delivery back to the pending queue
+// with a fresh attempt budget.
+func (r *WebhookRepository) RequeueDelivery(webhookID int, deliveryID int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE delivery_id = $1 AND webhook_id = $2 AND status = 'dead'
+	`
+
+	result, err := r.db.Exec(query, deliveryID, webhookID)
+	if err != nil {
+		return fmt.Errorf("update value error: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("dead delivery %d not found", deliveryID)
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/J0es1ick/shortli/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type WorkspaceRepository struct {
+	db *sqlx.DB
+}
+
+func NewWorkspaceRepository(db *sqlx.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{
+		db: db,
+	}
+}
+
+// CreateWorkspace creates a workspace with ownerID as its first owner.
+func (r *WorkspaceRepository) CreateWorkspace(name string, ownerID int) (*models.Workspace, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("begin tx error: %v", err)
+	}
+	defer tx.Rollback()
+
+	workspace := &models.Workspace{Role: models.RoleOwner}
+	err = tx.QueryRow(
+		"INSERT INTO workspaces (name) VALUES ($1) RETURNING workspace_id, name, created_at",
+		name,
+	).Scan(&workspace.ID, &workspace.Name, &workspace.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert value error: %v", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)",
+		workspace.ID, ownerID, models.RoleOwner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert value error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit error: %v", err)
+	}
+
+	return workspace, nil
+}
+
+// FindOwnerlessWorkspaces returns the workspaces nobody can administer, such
+// as the default workspace created by migration 7 for pre-existing links.
+func (r *WorkspaceRepository) FindOwnerlessWorkspaces() ([]models.Workspace, error) {
+	query := `
+		SELECT w.workspace_id, w.name, w.created_at
+		FROM workspaces w
+		WHERE NOT EXISTS (
+			SELECT 1 FROM workspace_members m
+			WHERE m.workspace_id = w.workspace_id AND m.role = 'owner'
+		)
+		ORDER BY w.workspace_id
+	`
+
+	workspaces := []models.Workspace{}
+	if err := r.db.Select(&workspaces, query); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return workspaces, nil
+}
+
+func (r *WorkspaceRepository) FindWorkspacesByUser(userID int) ([]models.Workspace, error) {
+	query := `
+		SELECT w.workspace_id, w.name, w.created_at, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.workspace_id
+		WHERE m.user_id = $1
+		ORDER BY m.created_at, w.workspace_id
+	`
+
+	workspaces := []models.Workspace{}
+	if err := r.db.Select(&workspaces, query, userID); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return workspaces, nil
+}
+
+func (r *WorkspaceRepository) FindMembership(workspaceID, userID int) (*models.Membership, error) {
+	query := `
+		SELECT workspace_id, user_id, role, created_at
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+	`
+
+	membership := &models.Membership{}
+	if err := r.db.Get(membership, query, workspaceID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("membership not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return membership, nil
+}
+
+// FindDefaultMembership returns the oldest membership of userID, used when a
+// request does not name a workspace explicitly.
+func (r *WorkspaceRepository) FindDefaultMembership(userID int) (*models.Membership, error) {
+	query := `
+		SELECT workspace_id, user_id, role, created_at
+		FROM workspace_members
+		WHERE user_id = $1
+		ORDER BY created_at, workspace_id
+		LIMIT 1
+	`
+
+	membership := &models.Membership{}
+	if err := r.db.Get(membership, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("membership not found")
+		}
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return membership, nil
+}
+
+func (r *WorkspaceRepository) FindMembers(workspaceID int) ([]models.Membership, error) {
+	query := `
+		SELECT m.workspace_id, m.user_id, u.email, u.name, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.created_at
+	`
+
+	members := []models.Membership{}
+	if err := r.db.Select(&members, query, workspaceID); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return members, nil
+}
+
+func (r *WorkspaceRepository) AddMember(workspaceID, userID int, role string) error {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+
+	if _, err := r.db.Exec(query, workspaceID, userID, role); err != nil {
+		return fmt.Errorf("insert value error: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateMemberRole changes the role of a member, refusing to demote the last
+// owner so that a workspace can always be managed.
+func (r *WorkspaceRepository) UpdateMemberRole(workspaceID, userID int, role string) error {
+	query := `
+		UPDATE workspace_members
+		SET role = $3
+		WHERE workspace_id = $1 AND user_id = $2
+			AND ($3 = 'owner' OR role <> 'owner' OR (
+				SELECT COUNT(*) FROM workspace_members
+				WHERE workspace_id = $1 AND role = 'owner'
+			) > 1)
+	`
+
+	return r.execMembershipChange(query, workspaceID, userID, role)
+}
+
+// RemoveMember removes a member unless it is the last owner.
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	query := `
+		DELETE FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+			AND (role <> 'owner' OR (
+				SELECT COUNT(*) FROM workspace_members
+				WHERE workspace_id = $1 AND role = 'owner'
+			) > 1)
+	`
+
+	return r.execMembershipChange(query, workspaceID, userID)
+}
+
+func (r *WorkspaceRepository) execMembershipChange(query string, workspaceID, userID int, args ...interface{}) error {
+	result, err := r.db.Exec(query, append([]interface{}{workspaceID, userID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("update value error: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		if _, err := r.FindMembership(workspaceID, userID); err != nil {
+			return err
+		}
+		return fmt.Errorf("workspace must keep at least one owner")
+	}
+
+	return nil
+}
+
+func (r *WorkspaceRepository) SaveInvitation(invitation *models.Invitation) (int, error) {
+	query := `
+		INSERT INTO workspace_invitations
+			(workspace_id, email, role, token_hash, invited_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING invitation_id
+	`
+
+	var id int
+	err := r.db.QueryRow(
+		query,
+		invitation.WorkspaceID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.CreatedAt,
+		invitation.ExpiresAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert value error: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *WorkspaceRepository) FindPendingInvitations(workspaceID int) ([]models.Invitation, error) {
+	query := `
+		SELECT invitation_id, workspace_id, email, role, token_hash, invited_by, created_at, expires_at, accepted_at
+		FROM workspace_invitations
+		WHERE workspace_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	invitations := []models.Invitation{}
+	if err := r.db.Select(&invitations, query, workspaceID); err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	return invitations, nil
+}
+
+func (r *WorkspaceRepository) DeleteInvitation(workspaceID, invitationID int) error {
+	result, err := r.db.Exec(
+		"DELETE FROM workspace_invitations WHERE invitation_id = $1 AND workspace_id = $2 AND accepted_at IS NULL",
+		invitationID, workspaceID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete value error: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("invitation %d not found", invitationID)
+	}
+
+	return nil
+}
+
+// AcceptInvitation marks the invitation identified by tokenHash as accepted
+// by user and grants the invited role. The invitation must be addressed to the
+// user's email and not be expired or already used.
+func (r *WorkspaceRepository) AcceptInvitation(tokenHash string, user *models.User) (*models.Membership, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("begin tx error: %v", err)
+	}
+	defer tx.Rollback()
+
+	invitation := &models.Invitation{}
+	err = tx.Get(invitation, `
+		UPDATE workspace_invitations
+		SET accepted_at = NOW()
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+			AND LOWER(email) = LOWER($2)
+		RETURNING invitation_id, workspace_id, email, role, token_hash, invited_by, created_at, expires_at, accepted_at
+	`, tokenHash, user.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("invitation not found")
+		}
+		return nil, fmt.Errorf("update value error: %v", err)
+	}
+
+	// Accepting never changes the role of someone who is already a member,
+	// so an invitation cannot be used to demote an owner.
+	_, err = tx.Exec(`
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`, invitation.WorkspaceID, user.ID, invitation.Role)
+	if err != nil {
+		return nil, fmt.Errorf("insert value error: %v", err)
+	}
+
+	membership := &models.Membership{}
+	err = tx.Get(membership, `
+		SELECT workspace_id, user_id, role, created_at
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+	`, invitation.WorkspaceID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("select error: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit error: %v", err)
+	}
+
+	return membership, nil
+}
@@ -0,0 +1,184 @@
+// Package hll implements HyperLogLog cardinality sketches.
+//
+// A sketch with precision p keeps 2^p one-byte registers and estimates the
+// number of distinct 64-bit hashes added to it with a standard error of
+// about 1.04/sqrt(2^p). Sketches of the same precision can be merged, which
+// yields the sketch of the union of their inputs.
+package hll
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	MinPrecision = 4
+	MaxPrecision = 16
+
+	// DefaultPrecision gives a standard error of about 1.6% in 4 KiB.
+	DefaultPrecision = 12
+)
+
+const (
+	encodingDense  = 0
+	encodingSparse = 1
+)
+
+type Sketch struct {
+	p         uint8
+	registers []uint8
+}
+
+func New(precision uint8) (*Sketch, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, fmt.Errorf("precision must be between %d and %d", MinPrecision, MaxPrecision)
+	}
+
+	return &Sketch{p: precision, registers: make([]uint8, 1<<precision)}, nil
+}
+
+func (s *Sketch) Precision() uint8 {
+	return s.p
+}
+
+// Add records a uniformly distributed 64-bit hash.
+func (s *Sketch) Add(hash uint64) {
+	index := hash >> (64 - s.p)
+	// The sentinel bit caps the rank when the remaining bits are all zero.
+	rank := uint8(bits.LeadingZeros64(hash<<s.p|1<<(s.p-1)) + 1)
+	if rank > s.registers[index] {
+		s.registers[index] = rank
+	}
+}
+
+// Merge folds other into s.
+func (s *Sketch) Merge(other *Sketch) error {
+	if other.p != s.p {
+		return fmt.Errorf("can't merge sketches of precision %d and %d", s.p, other.p)
+	}
+
+	for i, rank := range other.registers {
+		if rank > s.registers[i] {
+			s.registers[i] = rank
+		}
+	}
+	return nil
+}
+
+// Estimate returns the approximate number of distinct hashes added.
+func (s *Sketch) Estimate() uint64 {
+	m := float64(len(s.registers))
+
+	sum := 0.0
+	zeros := 0
+	for _, rank := range s.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha(len(s.registers)) * m * m / sum
+	// Linear counting is more accurate while many registers are empty.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
+
+// MarshalBinary encodes the sketch. Sketches with few non-empty registers,
+// such as those of rarely visited links, are stored as a list of
+// (index, rank) pairs instead of the full register array.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	nonZero := 0
+	for _, rank := range s.registers {
+		if rank != 0 {
+			nonZero++
+		}
+	}
+
+	if 3*nonZero >= len(s.registers) {
+		data := make([]byte, 2, 2+len(s.registers))
+		data[0], data[1] = s.p, encodingDense
+		return append(data, s.registers...), nil
+	}
+
+	data := make([]byte, 2, 2+3*nonZero)
+	data[0], data[1] = s.p, encodingSparse
+	for i, rank := range s.registers {
+		if rank != 0 {
+			data = binary.BigEndian.AppendUint16(data, uint16(i))
+			data = append(data, rank)
+		}
+	}
+	return data, nil
+}
+
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("sketch is truncated")
+	}
+
+	sketch, err := New(data[0])
+	if err != nil {
+		return err
+	}
+
+	// Add never produces a rank above maxRank.
+	maxRank := 65 - sketch.p
+
+	body := data[2:]
+	switch data[1] {
+	case encodingDense:
+		if len(body) != len(sketch.registers) {
+			return errors.New("sketch has the wrong number of registers")
+		}
+		for _, rank := range body {
+			if rank > maxRank {
+				return errors.New("sketch register rank out of range")
+			}
+		}
+		copy(sketch.registers, body)
+	case encodingSparse:
+		if len(body)%3 != 0 {
+			return errors.New("sketch is truncated")
+		}
+		// MarshalBinary writes the non-empty registers in index order.
+		previous := -1
+		for ; len(body) > 0; body = body[3:] {
+			index := int(binary.BigEndian.Uint16(body))
+			if index >= len(sketch.registers) {
+				return errors.New("sketch register index out of range")
+			}
+			if index <= previous {
+				return errors.New("sketch register indexes are not increasing")
+			}
+			if body[2] == 0 || body[2] > maxRank {
+				return errors.New("sketch register rank out of range")
+			}
+			sketch.registers[index] = body[2]
+			previous = index
+		}
+	default:
+		return fmt.Errorf("unknown sketch encoding %d", data[1])
+	}
+
+	*s = *sketch
+	return nil
+}
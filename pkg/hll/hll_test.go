@@ -0,0 +1,228 @@
+package hll
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// hashes returns n distinct, well mixed 64-bit values starting from seed
+// (splitmix64).
+func hashes(seed uint64, n int) []uint64 {
+	out := make([]uint64, n)
+	for i := range out {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		out[i] = z ^ (z >> 31)
+	}
+	return out
+}
+
+func newSketch(t *testing.T, precision uint8, values []uint64) *Sketch {
+	t.Helper()
+
+	s, err := New(precision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range values {
+		s.Add(v)
+	}
+	return s
+}
+
+func roundTrip(t *testing.T, s *Sketch) (*Sketch, []byte) {
+	t.Helper()
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Sketch{}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	return decoded, data
+}
+
+func TestNewPrecision(t *testing.T) {
+	for _, p := range []uint8{0, MinPrecision - 1, MaxPrecision + 1} {
+		if _, err := New(p); err == nil {
+			t.Errorf("New(%d) succeeded", p)
+		}
+	}
+	for _, p := range []uint8{MinPrecision, DefaultPrecision, MaxPrecision} {
+		if s, err := New(p); err != nil || s.Precision() != p {
+			t.Errorf("New(%d) = %v, %v", p, s, err)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		n        int
+		encoding byte
+	}{
+		{0, encodingSparse},
+		{1, encodingSparse},
+		{10, encodingSparse},
+		{100, encodingSparse},
+		{1000, encodingSparse},
+		{5000, encodingDense},
+		{50000, encodingDense},
+		{500000, encodingDense},
+	}
+
+	// Four standard errors of DefaultPrecision; linear counting is close
+	// to exact for small sets.
+	const tolerance = 4 * 1.04 / 64
+
+	for _, tt := range tests {
+		values := hashes(uint64(tt.n), tt.n)
+		s := newSketch(t, DefaultPrecision, values)
+		// Adding a value again never changes the sketch.
+		for _, v := range values[:len(values)/2] {
+			s.Add(v)
+		}
+
+		got := s.Estimate()
+		if diff := math.Abs(float64(got) - float64(tt.n)); diff > math.Max(tolerance*float64(tt.n), 1) {
+			t.Errorf("Estimate() of %d values = %d, off by %.1f%%", tt.n, got, 100*diff/float64(tt.n))
+		}
+
+		decoded, data := roundTrip(t, s)
+		if data[1] != tt.encoding {
+			t.Errorf("%d values encoded as %d, want %d", tt.n, data[1], tt.encoding)
+		}
+		if decoded.Estimate() != got {
+			t.Errorf("%d values: decoded Estimate() = %d, want %d", tt.n, decoded.Estimate(), got)
+		}
+	}
+}
+
+func TestSparseToDense(t *testing.T) {
+	s := newSketch(t, 8, nil)
+	m := 1 << 8
+
+	// The sparse form holds 3 bytes per non-empty register and gives way
+	// to the dense one once that is no longer smaller.
+	for i := 0; i < m; i++ {
+		s.Add(uint64(i)<<56 | 1<<40)
+
+		_, data := roundTrip(t, s)
+		nonZero := i + 1
+		if 3*nonZero < m {
+			if data[1] != encodingSparse || len(data) != 2+3*nonZero {
+				t.Fatalf("%d registers: encoding %d, %d bytes, want sparse in %d", nonZero, data[1], len(data), 2+3*nonZero)
+			}
+		} else if data[1] != encodingDense || len(data) != 2+m {
+			t.Fatalf("%d registers: encoding %d, %d bytes, want dense in %d", nonZero, data[1], len(data), 2+m)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	small := hashes(1, 100)
+	large := hashes(2, 20000)
+
+	tests := []struct {
+		name string
+		a, b []uint64
+	}{
+		{"sparse into sparse", small, hashes(3, 200)},
+		{"sparse into dense", large, small},
+		{"dense into sparse", small, large},
+		{"dense into dense", large, hashes(4, 30000)},
+		{"overlapping", large, large[:10000]},
+		{"empty", small, nil},
+	}
+
+	for _, tt := range tests {
+		// Merge decoded sketches so that each side went through its
+		// encoding.
+		a, _ := roundTrip(t, newSketch(t, DefaultPrecision, tt.a))
+		b, _ := roundTrip(t, newSketch(t, DefaultPrecision, tt.b))
+		if err := a.Merge(b); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		union := newSketch(t, DefaultPrecision, append(append([]uint64(nil), tt.a...), tt.b...))
+		if !bytes.Equal(a.registers, union.registers) {
+			t.Errorf("%s: merged sketch differs from the sketch of the union", tt.name)
+		}
+	}
+
+	other := newSketch(t, DefaultPrecision+1, small)
+	if err := newSketch(t, DefaultPrecision, small).Merge(other); err == nil {
+		t.Error("Merge of different precisions succeeded")
+	}
+}
+
+func TestEncodingIsStable(t *testing.T) {
+	// Sketches are persisted, so these bytes must never change.
+	s := newSketch(t, 4, []uint64{
+		0x1000000000000000, // register 1, all remaining bits zero: rank 61
+		0x2400000000000000, // register 2, rank 2
+	})
+
+	want := []byte{4, encodingSparse, 0, 1, 61, 0, 2, 2}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("MarshalBinary() = %v, want %v", data, want)
+	}
+
+	dense := append([]byte{4, encodingDense}, make([]byte, 16)...)
+	dense[2+3] = 5
+	decoded := &Sketch{}
+	if err := decoded.UnmarshalBinary(dense); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.registers[3] != 5 || decoded.Estimate() != 1 {
+		t.Errorf("decoded registers = %v", decoded.registers)
+	}
+}
+
+func TestUnmarshalRejectsCorruptInput(t *testing.T) {
+	dense := func(registers ...byte) []byte {
+		data := append([]byte{4, encodingDense}, make([]byte, 16)...)
+		copy(data[2:], registers)
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"header only precision", []byte{12}},
+		{"precision too low", []byte{3, encodingSparse}},
+		{"precision too high", []byte{17, encodingSparse}},
+		{"unknown encoding", []byte{4, 2}},
+		{"dense truncated", dense()[:17]},
+		{"dense too long", append(dense(), 0)},
+		{"dense rank out of range", dense(62)},
+		{"sparse truncated", []byte{4, encodingSparse, 0, 1}},
+		{"sparse index out of range", []byte{4, encodingSparse, 0, 16, 1}},
+		{"sparse zero rank", []byte{4, encodingSparse, 0, 1, 0}},
+		{"sparse rank out of range", []byte{4, encodingSparse, 0, 1, 62}},
+		{"sparse duplicate index", []byte{4, encodingSparse, 0, 1, 1, 0, 1, 2}},
+		{"sparse unordered", []byte{4, encodingSparse, 0, 2, 1, 0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		s := newSketch(t, 4, hashes(1, 3))
+		before := append([]uint8(nil), s.registers...)
+
+		if err := s.UnmarshalBinary(tt.data); err == nil {
+			t.Errorf("%s: UnmarshalBinary(%v) succeeded", tt.name, tt.data)
+		}
+		if s.p != 4 || !bytes.Equal(s.registers, before) {
+			t.Errorf("%s: failed UnmarshalBinary changed the sketch", tt.name)
+		}
+	}
+}
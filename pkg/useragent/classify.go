@@ -0,0 +1,177 @@
+package useragent
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// Class is the kind of client behind a request to a short link.
+type Class string
+
+const (
+	Human    Class = "human"
+	Bot      Class = "bot"
+	Prefetch Class = "prefetch"
+)
+
+// botFragments are User-Agent fragments of crawlers, link scanners, email
+// security gateways and HTTP libraries. Matching is case-insensitive.
+var botFragments = []string{
+	"bot",
+	"crawler",
+	"spider",
+	"scanner",
+	"headlesschrome",
+	"phantomjs",
+	"lighthouse",
+	"curl/",
+	"wget/",
+	"httpie/",
+	"python-requests",
+	"python-urllib",
+	"aiohttp",
+	"go-http-client",
+	"java/",
+	"okhttp",
+	"apache-httpclient",
+	"libwww-perl",
+	"node-fetch",
+	"axios/",
+	"barracuda",
+	"mimecast",
+	"proofpoint",
+	"forcepoint",
+	"trendmicro",
+	"symantec",
+	"sophos",
+	"zscaler",
+	"safelinks",
+	"urldefense",
+	"virustotal",
+	"urlscan",
+	"checkpoint",
+	"fortiguard",
+	"microsoft office",
+	"ms-office",
+	"preview",
+}
+
+// Classification is the verdict for a request and what triggered it.
+type Classification struct {
+	Class  Class
+	Reason string
+}
+
+// Classifier separates human clicks from bots, link scanners and browser
+// prefetches so that only the former are counted as clicks.
+type Classifier struct {
+	fragments []string
+	scanners  []netip.Prefix
+}
+
+// NewClassifier returns a classifier using the built-in User-Agent patterns
+// plus extra, and treating clients from scanners as bots.
+func NewClassifier(extra []string, scanners []netip.Prefix) *Classifier {
+	fragments := append([]string{}, botFragments...)
+	for _, fragment := range extra {
+		if fragment = strings.ToLower(strings.TrimSpace(fragment)); fragment != "" {
+			fragments = append(fragments, fragment)
+		}
+	}
+
+	return &Classifier{fragments: fragments, scanners: scanners}
+}
+
+// Classify inspects r, whose client address is clientIP.
+func (c *Classifier) Classify(r *http.Request, clientIP string) Classification {
+	if isPrefetch(r.Header) {
+		return Classification{Class: Prefetch, Reason: "prefetch header"}
+	}
+	if r.Method == http.MethodHead {
+		return Classification{Class: Bot, Reason: "HEAD request"}
+	}
+
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return Classification{Class: Bot, Reason: "missing user agent"}
+	}
+	if IsUnfurler(ua) {
+		return Classification{Class: Bot, Reason: "link unfurler"}
+	}
+	for _, fragment := range c.fragments {
+		if strings.Contains(ua, fragment) {
+			return Classification{Class: Bot, Reason: "user agent " + fragment}
+		}
+	}
+
+	if addr, err := netip.ParseAddr(clientIP); err == nil {
+		addr = addr.Unmap()
+		for _, prefix := range c.scanners {
+			if prefix.Contains(addr) {
+				return Classification{Class: Bot, Reason: "scanner range " + prefix.String()}
+			}
+		}
+	}
+
+	return Classification{Class: Human}
+}
+
+// isPrefetch recognises speculative loads announced by browsers: the
+// standard Sec-Purpose header and its legacy Purpose, X-Purpose and X-Moz
+// variants.
+func isPrefetch(header http.Header) bool {
+	for _, name := range []string{"Sec-Purpose", "Purpose", "X-Purpose", "X-Moz"} {
+		value := strings.ToLower(header.Get(name))
+		if strings.Contains(value, "prefetch") || strings.Contains(value, "prerender") || strings.Contains(value, "preview") {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadScannerRanges reads CIDR ranges of known link scanners, one per line.
+// Bare addresses are accepted as single-host ranges; blank lines and lines
+// starting with '#' are ignored.
+func LoadScannerRanges(path string) ([]netip.Prefix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open scanner ranges, %v", err)
+	}
+	defer f.Close()
+
+	return ParseScannerRanges(f)
+}
+
+func ParseScannerRanges(r io.Reader) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(text)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(text)
+			if addrErr != nil {
+				return nil, fmt.Errorf("scanner ranges line %d: expected a CIDR range or IP address", line)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read scanner ranges: %v", err)
+	}
+
+	return prefixes, nil
+}
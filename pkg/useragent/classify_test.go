@@ -0,0 +1,30 @@
+package useragent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClassifyScannerRanges(t *testing.T) {
+	c := NewClassifier(nil, []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")})
+
+	tests := []struct {
+		clientIP string
+		want     Class
+	}{
+		{"192.0.2.10", Bot},
+		{"::ffff:192.0.2.10", Bot},
+		{"198.51.100.1", Human},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/abc", nil)
+		r.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0")
+
+		if got := c.Classify(r, tt.clientIP).Class; got != tt.want {
+			t.Errorf("Classify(%s) = %s, want %s", tt.clientIP, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package useragent
+
+import "strings"
+
+// unfurlers are User-Agent fragments of crawlers that fetch a link to render
+// a preview card in chat apps and social networks. Matching is
+// case-insensitive.
+var unfurlers = []string{
+	"slackbot",
+	"slack-imgproxy",
+	"twitterbot",
+	"facebookexternalhit",
+	"facebot",
+	"linkedinbot",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"skypeuripreview",
+	"microsoft teams",
+	"redditbot",
+	"pinterestbot",
+	"embedly",
+	"iframely",
+	"vkshare",
+	"mastodon",
+	"bluesky cardyb",
+	"viber",
+	"line-poker",
+	"snapchat",
+	"tumblr",
+	"google-pagerenderer",
+}
+
+// IsUnfurler reports whether userAgent belongs to a link preview crawler.
+func IsUnfurler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, fragment := range unfurlers {
+		if strings.Contains(ua, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// CanonicalOptions control the optional, meaning-changing steps of
+// Canonicalize. The other steps never change where a URL points.
+type CanonicalOptions struct {
+	// StripFragment drops the #fragment.
+	StripFragment bool
+	// StripTracking drops analytics parameters such as utm_* and fbclid.
+	StripTracking bool
+}
+
+var trackingParams = map[string]struct{}{
+	"fbclid": {}, "gclid": {}, "gclsrc": {}, "dclid": {}, "gbraid": {}, "wbraid": {},
+	"msclkid": {}, "yclid": {}, "igshid": {}, "mc_cid": {}, "mc_eid": {},
+	"_ga": {}, "_gl": {}, "_hsenc": {}, "_hsmi": {}, "mkt_tok": {}, "twclid": {},
+	"ttclid": {}, "li_fat_id": {}, "oly_anon_id": {}, "oly_enc_id": {}, "vero_id": {},
+}
+
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// Canonicalize rewrites a normalized URL into a canonical form for
+// deduplication: lowercase scheme and host, IDN hosts in punycode, no default
+// port, dot segments resolved, percent-encoding normalized and query
+// parameters sorted by name.
+func Canonicalize(rawURL string, opts CanonicalOptions) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL format")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+		if ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+	} else if host, err = idna.Lookup.ToASCII(host); err != nil {
+		return "", fmt.Errorf("URL host is not a valid domain name")
+	}
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+	u.Host = host
+
+	canonicalPath := normalizeEscapes(u.EscapedPath())
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	} else {
+		trailing := strings.HasSuffix(canonicalPath, "/")
+		canonicalPath = path.Clean(canonicalPath)
+		if trailing && canonicalPath != "/" {
+			canonicalPath += "/"
+		}
+	}
+	u.RawPath = canonicalPath
+	u.Path, _ = url.PathUnescape(canonicalPath)
+
+	u.RawQuery = canonicalQuery(u.RawQuery, opts.StripTracking)
+	u.ForceQuery = false
+
+	if opts.StripFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+
+	return u.String(), nil
+}
+
+// CanonicalHash is the hex SHA-256 of a canonical URL, stored for dedup
+// lookups.
+func CanonicalHash(canonicalURL string) string {
+	sum := sha256.Sum256([]byte(canonicalURL))
+	return hex.EncodeToString(sum[:])
+}
+
+func canonicalQuery(rawQuery string, stripTracking bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	type param struct{ key, pair string }
+	var params []param
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		pair = normalizeEscapes(strings.ReplaceAll(pair, "+", "%20"))
+		key, _, _ := strings.Cut(pair, "=")
+
+		if stripTracking {
+			name, _ := url.QueryUnescape(key)
+			name = strings.ToLower(name)
+			if _, ok := trackingParams[name]; ok || strings.HasPrefix(name, "utm_") {
+				continue
+			}
+		}
+
+		params = append(params, param{key: key, pair: pair})
+	}
+
+	// Repeated keys keep their relative order, which can be significant.
+	sort.SliceStable(params, func(i, j int) bool { return params[i].key < params[j].key })
+
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.pair
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// normalizeEscapes decodes percent-escapes of unreserved characters and
+// uppercases the hex digits of all others, per RFC 3986 section 6.2.2.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			c := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(c) {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('%')
+				b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// confusables maps Cyrillic, Greek and extended Latin letters to the basic
+// Latin letters they are visually indistinguishable from in common fonts.
+var confusables = map[rune]rune{
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'к': 'k', 'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's',
+	'т': 't', 'ц': 'u', 'ѵ': 'v', 'ԝ': 'w', 'х': 'x', 'у': 'y', 'ү': 'y',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	'ɡ': 'g', 'ı': 'i', 'ɩ': 'i', 'ȷ': 'j', 'ɑ': 'a', 'ɒ': 'o', 'ʏ': 'y', 'ᴠ': 'v',
+}
+
+// ProtectedDomains are frequently impersonated domains. A host whose
+// confusable skeleton equals one of them, without being it, is refused.
+var ProtectedDomains = []string{
+	"google.com", "gmail.com", "youtube.com", "facebook.com", "instagram.com",
+	"whatsapp.com", "apple.com", "icloud.com", "microsoft.com", "live.com",
+	"outlook.com", "office.com", "amazon.com", "paypal.com", "netflix.com",
+	"linkedin.com", "twitter.com", "x.com", "github.com", "dropbox.com",
+	"binance.com", "coinbase.com", "steamcommunity.com", "telegram.org",
+}
+
+// Lookalike refuses internationalized hosts that imitate Latin domains:
+// labels mixing Latin with Cyrillic or Greek letters, and hosts whose
+// skeleton matches a protected domain. Labels written wholly in Cyrillic or
+// Greek are otherwise allowed, even when every letter is confusable, since
+// words such as "мое" or "сайт" are ordinary names in those scripts;
+// lookalikes of unprotected domains are not caught.
+type Lookalike struct {
+	protected map[string]struct{}
+}
+
+func NewLookalike(protected []string) *Lookalike {
+	l := &Lookalike{protected: make(map[string]struct{}, len(protected))}
+	for _, domain := range protected {
+		l.protected[strings.ToLower(domain)] = struct{}{}
+	}
+	return l
+}
+
+func (l *Lookalike) Screen(u *url.URL) error {
+	host, err := idna.Lookup.ToUnicode(u.Hostname())
+	if err != nil {
+		return fmt.Errorf("URL host is not a valid domain name")
+	}
+
+	labels := strings.Split(host, ".")
+	skeleton := make([]string, len(labels))
+
+	for i, label := range labels {
+		latin, other := 0, 0
+		var b strings.Builder
+
+		for _, r := range label {
+			switch {
+			case r < unicode.MaxASCII:
+				if unicode.IsLetter(r) {
+					latin++
+				}
+				b.WriteRune(r)
+			case unicode.In(r, unicode.Cyrillic, unicode.Greek):
+				other++
+				fallthrough
+			default:
+				if mapped, ok := confusables[r]; ok {
+					r = mapped
+				}
+				b.WriteRune(r)
+			}
+		}
+
+		if latin > 0 && other > 0 {
+			return fmt.Errorf("URL host mixes Latin and non-Latin letters")
+		}
+
+		skeleton[i] = b.String()
+	}
+
+	if strings.Join(skeleton, ".") == host {
+		return nil
+	}
+
+	for i := range skeleton {
+		if _, ok := l.protected[strings.Join(skeleton[i:], ".")]; ok {
+			return fmt.Errorf("URL host imitates %s", strings.Join(skeleton[i:], "."))
+		}
+	}
+
+	return nil
+}
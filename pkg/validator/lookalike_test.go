@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLookalikeScreen(t *testing.T) {
+	lookalike := NewLookalike(ProtectedDomains)
+
+	const (
+		mixed   = "mixes Latin and non-Latin"
+		invalid = "not a valid domain name"
+	)
+
+	tests := []struct {
+		host string
+		// want is a fragment of the expected error, empty if the host is
+		// allowed.
+		want string
+	}{
+		// Plain ASCII hosts, protected ones included.
+		{"example.com", ""},
+		{"google.com", ""},
+		{"mail.google.com", ""},
+		{"xn--zz.com", invalid},
+
+		// Labels mixing Latin with Cyrillic or Greek letters.
+		{"pаypal.com", mixed},
+		{"gооgle.com", mixed},
+		{"login.micrοsoft.com", mixed},
+		{"xn--pypal-4ve.com", mixed},
+
+		// Skeletons of protected domains.
+		{"аррӏе.com", "imitates apple.com"},
+		{"xn--80ak6aa92e.com", "imitates apple.com"},
+		{"secure.раураӏ.com", "imitates paypal.com"},
+		{"χ.com", "imitates x.com"},
+		{"ɡoogle.com", "imitates google.com"},
+
+		// Wholly Cyrillic or Greek labels whose skeleton is not protected,
+		// even when every letter is confusable. This lets lookalikes of
+		// unprotected domains such as "сосо.com" (coco.com) through.
+		{"мое.рф", ""},
+		{"xn--e1ang.xn--p1ai", ""},
+		{"сосо.com", ""},
+		{"пример.рф", ""},
+		{"ελλάδα.gr", ""},
+		{"ονο.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			u, err := url.Parse("https://" + tt.host + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = lookalike.Screen(u)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Screen(%q) = %v, want nil", tt.host, err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Screen(%q) = %v, want error containing %q", tt.host, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const resolveTimeout = 3 * time.Second
+
+// Resolver looks up the addresses of a host. *net.Resolver implements it;
+// tests can substitute a static table.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// blockedPrefixes are special-purpose ranges that IsPrivate, IsLoopback and
+// friends do not cover.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT, Alibaba metadata
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+	netip.MustParsePrefix("100::/64"),        // discard
+	netip.MustParsePrefix("2001::/32"),       // Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("fec0::/10"),       // deprecated site-local
+}
+
+// embeddedIPv4 are IPv6 ranges that carry an IPv4 address, which is checked
+// in their place: NAT64 in the low 32 bits, 6to4 in bits 16-48.
+var (
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFour   = netip.MustParsePrefix("2002::/16")
+)
+
+// blockedHostnames are names of cloud metadata services and local-only
+// names that must never be a destination, whatever they resolve to.
+var blockedHostnames = []string{
+	"localhost", "metadata", "metadata.google.internal", "instance-data",
+	"instance-data.ec2.internal",
+}
+
+var blockedSuffixes = []string{".localhost", ".local", ".internal", ".localdomain", ".home.arpa"}
+
+// IsPublicAddr reports whether addr is a globally routable unicast address.
+// IPv4-mapped, NAT64 and 6to4 addresses are judged by the IPv4 address they
+// embed.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if addr.Is6() {
+		if nat64Prefix.Contains(addr) {
+			b := addr.As16()
+			return IsPublicAddr(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}))
+		}
+		if sixToFour.Contains(addr) {
+			b := addr.As16()
+			return IsPublicAddr(netip.AddrFrom4([4]byte{b[2], b[3], b[4], b[5]}))
+		}
+	}
+
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsMulticast() ||
+		addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NetworkGuard refuses destinations that point at loopback, private,
+// link-local, metadata or otherwise non-public addresses, either literally,
+// through a non-standard numeric encoding, or through DNS.
+type NetworkGuard struct {
+	resolver Resolver
+}
+
+// NewNetworkGuard uses resolver for host lookups, or net.DefaultResolver if
+// it is nil.
+func NewNetworkGuard(resolver Resolver) *NetworkGuard {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &NetworkGuard{resolver: resolver}
+}
+
+func (g *NetworkGuard) Screen(u *url.URL) error {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	return g.CheckHost(ctx, u.Hostname())
+}
+
+func (g *NetworkGuard) CheckHost(ctx context.Context, host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	errInternal := fmt.Errorf("URL points to a private or internal address")
+
+	for _, name := range blockedHostnames {
+		if host == name {
+			return errInternal
+		}
+	}
+	for _, suffix := range blockedSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return errInternal
+		}
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddr(addr) {
+			return errInternal
+		}
+		return nil
+	}
+
+	// Browsers and many HTTP clients accept inet_aton forms such as
+	// 2130706433, 0177.0.0.1 or 0x7f.1 for 127.0.0.1. They have no
+	// legitimate use in a short link, so they are refused outright.
+	if addr, ok := parseLegacyIPv4(host); ok {
+		if !IsPublicAddr(addr) {
+			return errInternal
+		}
+		return fmt.Errorf("URL host uses a non-standard IP address encoding")
+	}
+
+	addrs, err := g.resolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("URL host could not be resolved")
+	}
+
+	for _, ipAddr := range addrs {
+		addr, ok := netip.AddrFromSlice(ipAddr.IP)
+		if !ok || !IsPublicAddr(addr) {
+			return errInternal
+		}
+	}
+
+	return nil
+}
+
+// DialControl is a net.Dialer Control function that refuses connections to
+// non-public addresses. Outgoing HTTP clients use it so that a host which
+// re-resolves to an internal address after validation is still blocked.
+func DialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("refusing to dial %s: %v", address, err)
+	}
+
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("refusing to dial non-public address %s", addrPort.Addr())
+	}
+
+	return nil
+}
+
+// parseLegacyIPv4 parses the numeric host forms accepted by inet_aton: one
+// to four dot-separated parts in decimal, octal (leading 0) or hex (0x),
+// where the last part fills all remaining bytes.
+func parseLegacyIPv4(host string) (netip.Addr, bool) {
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return netip.Addr{}, false
+		}
+
+		base := 10
+		switch {
+		case strings.HasPrefix(part, "0x") || strings.HasPrefix(part, "0X"):
+			part, base = part[2:], 16
+		case len(part) > 1 && part[0] == '0':
+			part, base = part[1:], 8
+		}
+		if part == "" && base == 16 {
+			part = "0"
+		}
+
+		value, err := strconv.ParseUint(part, base, 32)
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		values[i] = value
+	}
+
+	var ip uint64
+	for i, value := range values[:len(values)-1] {
+		if value > 0xff {
+			return netip.Addr{}, false
+		}
+		ip |= value << (8 * (3 - i))
+	}
+
+	last := values[len(values)-1]
+	if last >= 1<<(8*(5-len(values))) {
+		return netip.Addr{}, false
+	}
+	ip |= last
+
+	return netip.AddrFrom4([4]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)}), true
+}
@@ -0,0 +1,190 @@
+package validator
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// staticResolver answers lookups from a fixed table and fails for every
+// other host.
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	answers, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	addrs := make([]net.IPAddr, len(answers))
+	for i, answer := range answers {
+		addrs[i] = net.IPAddr{IP: net.ParseIP(answer)}
+	}
+	return addrs, nil
+}
+
+func TestCheckHost(t *testing.T) {
+	resolver := staticResolver{
+		"example.com":        {"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"},
+		"internal.example":   {"10.0.0.5"},
+		"mixed.example":      {"93.184.216.34", "127.0.0.1"},
+		"metadata.example":   {"169.254.169.254"},
+		"mapped.example":     {"::ffff:192.168.1.1"},
+		"nat64.example":      {"64:ff9b::a9fe:a9fe"},
+		"cgnat.example":      {"100.100.100.200"},
+		"ipv6-local.example": {"fd00::1"},
+		"empty.example":      {},
+	}
+	guard := NewNetworkGuard(resolver)
+
+	const (
+		internal = "private or internal"
+		encoding = "non-standard IP address encoding"
+		resolve  = "could not be resolved"
+	)
+
+	tests := []struct {
+		host string
+		// want is a fragment of the expected error, empty if the host is
+		// allowed.
+		want string
+	}{
+		// Names refused whatever they resolve to.
+		{"localhost", internal},
+		{"LOCALHOST.", internal},
+		{"api.localhost", internal},
+		{"printer.local", internal},
+		{"metadata.google.internal", internal},
+		{"router.home.arpa", internal},
+
+		// Literal addresses.
+		{"93.184.216.34", ""},
+		{"127.0.0.1", internal},
+		{"10.1.2.3", internal},
+		{"172.16.0.1", internal},
+		{"192.168.0.1", internal},
+		{"169.254.169.254", internal},
+		{"100.64.0.1", internal},
+		{"0.0.0.0", internal},
+		{"255.255.255.255", internal},
+		{"2606:4700:4700::1111", ""},
+		{"::1", internal},
+		{"::", internal},
+		{"fe80::1", internal},
+		{"fc00::1", internal},
+		{"2001:db8::1", internal},
+
+		// IPv6 forms that embed an IPv4 address.
+		{"::ffff:127.0.0.1", internal},
+		{"::ffff:10.0.0.1", internal},
+		{"::ffff:93.184.216.34", ""},
+		{"64:ff9b::7f00:1", internal},
+		{"64:ff9b::a9fe:a9fe", internal},
+		{"64:ff9b::5db8:d822", ""},
+		{"2002:7f00:1::", internal},
+		{"2002:c0a8:101::1", internal},
+		{"2002:5db8:d822::1", ""},
+
+		// inet_aton encodings.
+		{"2130706433", internal},
+		{"0177.0.0.1", internal},
+		{"0x7f.1", internal},
+		{"0x7f000001", internal},
+		{"017700000001", internal},
+		{"1572395042", encoding},
+		{"0x5d.0xb8.0xd8.0x22", encoding},
+
+		// DNS answers.
+		{"example.com", ""},
+		{"example.com.", ""},
+		{"internal.example", internal},
+		{"mixed.example", internal},
+		{"metadata.example", internal},
+		{"mapped.example", internal},
+		{"nat64.example", internal},
+		{"cgnat.example", internal},
+		{"ipv6-local.example", internal},
+		{"empty.example", resolve},
+		{"missing.example", resolve},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := guard.CheckHost(context.Background(), tt.host)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("CheckHost(%q) = %v, want nil", tt.host, err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("CheckHost(%q) = %v, want error containing %q", tt.host, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLegacyIPv4(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"2130706433", "127.0.0.1"},
+		{"0177.0.0.1", "127.0.0.1"},
+		{"0x7f.1", "127.0.0.1"},
+		{"0x7F.0.0.1", "127.0.0.1"},
+		{"127.1", "127.0.0.1"},
+		{"127.0.1", "127.0.0.1"},
+		{"0x7f000001", "127.0.0.1"},
+		{"0xa9.0xfe.0xa9.0xfe", "169.254.169.254"},
+		{"10.0xffffff", "10.255.255.255"},
+		{"0", "0.0.0.0"},
+		{"0x", "0.0.0.0"},
+		{"4294967295", "255.255.255.255"},
+
+		// Not an address.
+		{"4294967296", ""},
+		{"256.0.0.1", ""},
+		{"1.2.3.4.5", ""},
+		{"1..2", ""},
+		{"127.0.0.", ""},
+		{"08.0.0.1", ""},
+		{"0xg", ""},
+		{"127.0.0x10000", ""},
+		{"example.com", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseLegacyIPv4(tt.host)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseLegacyIPv4(%q) = %v, want no address", tt.host, got)
+			}
+			continue
+		}
+		if !ok || got != netip.MustParseAddr(tt.want) {
+			t.Errorf("parseLegacyIPv4(%q) = %v, %v, want %s", tt.host, got, ok, tt.want)
+		}
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:4700:4700::1111]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::ffff:169.254.169.254]:80", false},
+		{"[64:ff9b::a00:1]:80", false},
+		{"example.com:80", false},
+	}
+
+	for _, tt := range tests {
+		err := DialControl("tcp", tt.address, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("DialControl(%q) = %v, want allowed %v", tt.address, err, tt.allowed)
+		}
+	}
+}
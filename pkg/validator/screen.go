@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Screener decides whether a syntactically valid destination may be
+// shortened. Screen receives the normalized URL and returns a user-facing
+// error when the destination is refused.
+type Screener interface {
+	Screen(u *url.URL) error
+}
+
+// Validator combines ValidateURL with destination screening. Hosts on the
+// allow list skip every screener; everything else must pass all of them.
+type Validator struct {
+	// Canonical selects the optional steps of CanonicalHash.
+	Canonical CanonicalOptions
+
+	allow     *DomainList
+	screeners []Screener
+}
+
+func New(allow *DomainList, screeners ...Screener) *Validator {
+	return &Validator{
+		allow:     allow,
+		screeners: screeners,
+	}
+}
+
+// Validate normalizes inputURL with ValidateURL and screens the result.
+func (v *Validator) Validate(inputURL string) (string, error) {
+	normalized, err := ValidateURL(inputURL)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := url.Parse(normalized)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL format")
+	}
+
+	if v.allow != nil && v.allow.Matches(parsed.Hostname()) {
+		return normalized, nil
+	}
+
+	for _, screener := range v.screeners {
+		if err := screener.Screen(parsed); err != nil {
+			return "", err
+		}
+	}
+
+	return normalized, nil
+}
+
+// ForBatch returns a copy of v for validating many URLs in one go, such as
+// an import. Screeners that judge only the host remember their verdict per
+// host, so each host is resolved once however many URLs point to it. The
+// copy is not safe for concurrent use.
+func (v *Validator) ForBatch() *Validator {
+	batch := *v
+	batch.screeners = make([]Screener, len(v.screeners))
+	for i, screener := range v.screeners {
+		switch screener.(type) {
+		case *DomainList, shortenerList, *Lookalike, *NetworkGuard:
+			screener = &hostCache{screener: screener, verdicts: make(map[string]error)}
+		}
+		batch.screeners[i] = screener
+	}
+	return &batch
+}
+
+// hostCache remembers the verdict of a host-only screener per host.
+type hostCache struct {
+	screener Screener
+	verdicts map[string]error
+}
+
+func (c *hostCache) Screen(u *url.URL) error {
+	host := u.Hostname()
+	if err, ok := c.verdicts[host]; ok {
+		return err
+	}
+
+	err := c.screener.Screen(u)
+	c.verdicts[host] = err
+	return err
+}
+
+// CanonicalHash returns the deduplication hash of a URL returned by Validate.
+func (v *Validator) CanonicalHash(normalizedURL string) (string, error) {
+	canonical, err := Canonicalize(normalizedURL, v.Canonical)
+	if err != nil {
+		return "", err
+	}
+	return CanonicalHash(canonical), nil
+}
+
+// DomainList matches hosts against exact domains ("example.com") and
+// wildcard suffixes ("*.example.com", which matches every subdomain but not
+// example.com itself).
+type DomainList struct {
+	exact    map[string]struct{}
+	suffixes []string
+	reason   string
+}
+
+// NewDomainList builds a list from patterns. reason is the error returned by
+// Screen for matching hosts.
+func NewDomainList(patterns []string, reason string) *DomainList {
+	list := &DomainList{exact: make(map[string]struct{}), reason: reason}
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		switch {
+		case pattern == "":
+		case strings.HasPrefix(pattern, "*."):
+			list.suffixes = append(list.suffixes, pattern[1:])
+		default:
+			list.exact[pattern] = struct{}{}
+		}
+	}
+
+	return list
+}
+
+func (l *DomainList) Matches(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if _, ok := l.exact[host]; ok {
+		return true
+	}
+	for _, suffix := range l.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *DomainList) Len() int {
+	return len(l.exact) + len(l.suffixes)
+}
+
+func (l *DomainList) Screen(u *url.URL) error {
+	if l.Matches(u.Hostname()) {
+		return fmt.Errorf("%s", l.reason)
+	}
+	return nil
+}
+
+// KnownShorteners are public URL shorteners whose links are refused as
+// destinations, since chaining shorteners hides the real target.
+var KnownShorteners = []string{
+	"bit.ly", "bitly.com", "j.mp", "tinyurl.com", "t.co", "goo.gl", "ow.ly",
+	"is.gd", "v.gd", "buff.ly", "cutt.ly", "rebrand.ly", "tiny.cc", "shorturl.at",
+	"rb.gy", "t.ly", "bl.ink", "short.io", "s.id", "lnkd.in", "trib.al",
+	"soo.gd", "clck.ru", "qr.ae", "adf.ly", "shorte.st", "yourls.org",
+}
+
+// NewShortenerList refuses links to other shorteners and to ownDomains, the
+// hosts this instance serves short links on, which would create redirect
+// loops.
+func NewShortenerList(ownDomains []string) Screener {
+	return shortenerList{
+		own:    NewDomainList(ownDomains, "URL points to a short link of this service"),
+		others: NewDomainList(KnownShorteners, "URL points to another URL shortener"),
+	}
+}
+
+type shortenerList struct {
+	own    *DomainList
+	others *DomainList
+}
+
+func (s shortenerList) Screen(u *url.URL) error {
+	if err := s.own.Screen(u); err != nil {
+		return err
+	}
+	return s.others.Screen(u)
+}
@@ -0,0 +1,208 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// refuseAll refuses every URL and records the ones it was asked about.
+type refuseAll struct {
+	screened []string
+}
+
+func (r *refuseAll) Screen(u *url.URL) error {
+	r.screened = append(r.screened, u.String())
+	return fmt.Errorf("refused %s", u.Hostname())
+}
+
+type allowAll struct{}
+
+func (allowAll) Screen(u *url.URL) error { return nil }
+
+func TestDomainListMatches(t *testing.T) {
+	list := NewDomainList([]string{"Example.COM", "*.evil.test", " trailing.test. ", "", "  "}, "blocked")
+
+	if list.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", list.Len())
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.com.", true},
+		{"www.example.com", false},
+		{"example.com.au", false},
+		{"trailing.test", true},
+		{"evil.test", false},
+		{"a.evil.test", true},
+		{"a.b.evil.test", true},
+		{"notevil.test", false},
+		{"evil.test.example", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := list.Matches(tt.host); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestDomainListScreen(t *testing.T) {
+	list := NewDomainList([]string{"*.example.com"}, "URL domain is blocked")
+
+	u, _ := url.Parse("https://www.example.com:8443/path")
+	if err := list.Screen(u); err == nil || err.Error() != "URL domain is blocked" {
+		t.Errorf("Screen(%q) = %v, want the list's reason", u, err)
+	}
+	u, _ = url.Parse("https://example.com/path")
+	if err := list.Screen(u); err != nil {
+		t.Errorf("Screen(%q) = %v, want nil", u, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	allow := NewDomainList([]string{"trusted.test", "*.partner.test"}, "")
+
+	tests := []struct {
+		input string
+		want  string
+		// err is a fragment of the expected error, empty if the URL passes.
+		err string
+		// screened reports whether the screeners see the URL.
+		screened bool
+	}{
+		// Allow-listed hosts skip the screeners.
+		{"https://trusted.test/a", "https://trusted.test/a", "", false},
+		{"TRUSTED.test/a", "https://trusted.test/a", "", false},
+		{"http://docs.partner.test", "http://docs.partner.test", "", false},
+		{"https://a.b.partner.test/x", "https://a.b.partner.test/x", "", false},
+
+		// Wildcards do not cover the domain itself, nor do exact entries
+		// cover subdomains.
+		{"https://partner.test/", "", "refused partner.test", true},
+		{"https://www.trusted.test/", "", "refused www.trusted.test", true},
+		{"https://example.com/", "", "refused example.com", true},
+
+		// Invalid input is refused before screening, allow-listed or not.
+		{"ftp://trusted.test/", "", "scheme not allowed", false},
+		{"", "", "cannot be empty", false},
+	}
+
+	for _, tt := range tests {
+		screener := &refuseAll{}
+		v := New(allow, allowAll{}, screener)
+
+		got, err := v.Validate(tt.input)
+		switch {
+		case tt.err == "" && (err != nil || got != tt.want):
+			t.Errorf("Validate(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Validate(%q) = %q, %v, want error containing %q", tt.input, got, err, tt.err)
+		}
+		if screened := len(screener.screened) > 0; screened != tt.screened {
+			t.Errorf("Validate(%q) screened %v, want %v", tt.input, screened, tt.screened)
+		}
+	}
+}
+
+func TestValidateStopsAtFirstRefusal(t *testing.T) {
+	first, second := &refuseAll{}, &refuseAll{}
+	v := New(nil, first, second)
+
+	if _, err := v.Validate("https://example.com/"); err == nil {
+		t.Fatal("Validate() succeeded")
+	}
+	if len(first.screened) != 1 || len(second.screened) != 0 {
+		t.Errorf("screeners saw %d and %d URLs, want 1 and 0", len(first.screened), len(second.screened))
+	}
+}
+
+func TestShortenerList(t *testing.T) {
+	screener := NewShortenerList([]string{"sho.rt", "*.links.test"})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://sho.rt/abc", "short link of this service"},
+		{"https://go.links.test/abc", "short link of this service"},
+		{"https://bit.ly/abc", "another URL shortener"},
+		{"https://TinyURL.com/abc", "another URL shortener"},
+		{"https://links.test/abc", ""},
+		{"https://example.com/", ""},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = screener.Screen(u)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("Screen(%q) = %v, want nil", tt.url, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("Screen(%q) = %v, want error containing %q", tt.url, err, tt.want)
+		}
+	}
+}
+
+// countingResolver counts the lookups made through it.
+type countingResolver struct {
+	staticResolver
+	lookups int
+}
+
+func (r *countingResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.lookups++
+	return r.staticResolver.LookupIPAddr(ctx, host)
+}
+
+func TestForBatch(t *testing.T) {
+	resolver := &countingResolver{staticResolver: staticResolver{
+		"example.com":      {"93.184.216.34"},
+		"internal.example": {"10.0.0.5"},
+	}}
+	perURL := &refuseAll{}
+	v := New(nil, NewNetworkGuard(resolver), perURL)
+	batch := v.ForBatch()
+
+	inputs := []string{
+		"https://example.com/a",
+		"https://EXAMPLE.com/b",
+		"http://example.com/c?d=1",
+		"https://internal.example/a",
+		"https://internal.example/b",
+	}
+	for _, input := range inputs {
+		_, err := batch.Validate(input)
+		if err == nil {
+			t.Fatalf("Validate(%q) succeeded", input)
+		}
+		if strings.Contains(input, "internal") != strings.Contains(err.Error(), "private or internal") {
+			t.Errorf("Validate(%q) = %v", input, err)
+		}
+	}
+
+	if resolver.lookups != 2 {
+		t.Errorf("batch resolved %d times, want once per host", resolver.lookups)
+	}
+	// Screeners that look past the host still see every URL.
+	if len(perURL.screened) != 3 {
+		t.Errorf("per-URL screener saw %d URLs, want 3", len(perURL.screened))
+	}
+
+	v.Validate("https://example.com/a")
+	v.Validate("https://example.com/b")
+	if resolver.lookups != 4 {
+		t.Errorf("the original validator resolved %d times in total, want 4", resolver.lookups)
+	}
+}
@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ThreatList screens URLs against SHA-256 hash prefixes of malicious URL
+// expressions, in the format used by Safe Browsing update APIs. The file
+// holds one hex-encoded prefix of 4 to 32 bytes per line; blank lines and
+// lines starting with '#' are ignored.
+//
+// Safe Browsing clients confirm prefix hits against full hashes; a local
+// list has no such lookup, so any prefix hit is treated as a match.
+type ThreatList struct {
+	prefixes map[int]map[string]struct{}
+	lengths  []int
+}
+
+func LoadThreatList(path string) (*ThreatList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open threat list, %v", err)
+	}
+	defer f.Close()
+
+	return ParseThreatList(f)
+}
+
+func ParseThreatList(r io.Reader) (*ThreatList, error) {
+	list := &ThreatList{prefixes: make(map[int]map[string]struct{})}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		prefix, err := hex.DecodeString(text)
+		if err != nil || len(prefix) < 4 || len(prefix) > sha256.Size {
+			return nil, fmt.Errorf("threat list line %d: expected 4 to 32 hex-encoded bytes", line)
+		}
+
+		set, ok := list.prefixes[len(prefix)]
+		if !ok {
+			set = make(map[string]struct{})
+			list.prefixes[len(prefix)] = set
+			list.lengths = append(list.lengths, len(prefix))
+		}
+		set[string(prefix)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read threat list, %v", err)
+	}
+
+	sort.Ints(list.lengths)
+	return list, nil
+}
+
+func (t *ThreatList) Len() int {
+	n := 0
+	for _, set := range t.prefixes {
+		n += len(set)
+	}
+	return n
+}
+
+func (t *ThreatList) Screen(u *url.URL) error {
+	for _, expression := range urlExpressions(u) {
+		sum := sha256.Sum256([]byte(expression))
+		for _, length := range t.lengths {
+			if _, ok := t.prefixes[length][string(sum[:length])]; ok {
+				return fmt.Errorf("URL is on the threat list")
+			}
+		}
+	}
+	return nil
+}
+
+// urlExpressions returns the host-suffix/path-prefix combinations that Safe
+// Browsing hashes for a URL: up to five host suffixes and up to six paths.
+func urlExpressions(u *url.URL) []string {
+	host := strings.Trim(strings.ToLower(u.Hostname()), ".")
+
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		labels := strings.Split(host, ".")
+		start := max(1, len(labels)-5)
+		for i := start; i < len(labels)-1 && len(hosts) < 5; i++ {
+			hosts = append(hosts, strings.Join(labels[i:], "."))
+		}
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	paths := []string{}
+	if u.RawQuery != "" {
+		paths = append(paths, path+"?"+u.RawQuery)
+	}
+	paths = append(paths, path)
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	prefix := "/"
+	if path != "/" {
+		paths = append(paths, prefix)
+	}
+	for i := 0; i < len(segments)-1 && i < 3; i++ {
+		prefix += segments[i] + "/"
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+	}
+
+	expressions := make([]string, 0, len(hosts)*len(paths))
+	for _, h := range hosts {
+		for _, p := range paths {
+			expressions = append(expressions, h+p)
+		}
+	}
+
+	return expressions
+}
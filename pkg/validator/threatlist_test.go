@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// prefix returns the hex-encoded SHA-256 prefix of n bytes of expression.
+func prefix(expression string, n int) string {
+	sum := sha256.Sum256([]byte(expression))
+	return hex.EncodeToString(sum[:n])
+}
+
+func TestURLExpressions(t *testing.T) {
+	// Examples from the Safe Browsing URL hashing documentation.
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"http://a.b.c/1/2.html?param=1", []string{
+			"a.b.c/1/2.html?param=1", "a.b.c/1/2.html", "a.b.c/", "a.b.c/1/",
+			"b.c/1/2.html?param=1", "b.c/1/2.html", "b.c/", "b.c/1/",
+		}},
+		{"http://a.b.c.d.e.f.g/1.html", []string{
+			"a.b.c.d.e.f.g/1.html", "a.b.c.d.e.f.g/",
+			"c.d.e.f.g/1.html", "c.d.e.f.g/",
+			"d.e.f.g/1.html", "d.e.f.g/",
+			"e.f.g/1.html", "e.f.g/",
+			"f.g/1.html", "f.g/",
+		}},
+		{"http://1.2.3.4/1/", []string{"1.2.3.4/1/", "1.2.3.4/"}},
+		{"http://a.b/saw-cgi/eBayISAPI.dll/", []string{
+			"a.b/saw-cgi/eBayISAPI.dll/", "a.b/", "a.b/saw-cgi/",
+		}},
+		{"http://a.b/", []string{"a.b/"}},
+		{"http://a.b", []string{"a.b/"}},
+		{"http://A.B.C./", []string{"a.b.c/", "b.c/"}},
+		{"http://a.b/1/2/3/4/5/6.html", []string{
+			"a.b/1/2/3/4/5/6.html", "a.b/", "a.b/1/", "a.b/1/2/", "a.b/1/2/3/",
+		}},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := urlExpressions(u); !slices.Equal(got, tt.want) {
+			t.Errorf("urlExpressions(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestParseThreatList(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		// want is the number of prefixes, or -1 if the list is refused.
+		want int
+	}{
+		{"empty", "", 0},
+		{"comments and blank lines", "# header\n\n  \n# " + prefix("a.b/", 4) + "\n", 0},
+		{"prefix lengths", prefix("a.b/", 4) + "\n" + prefix("c.d/", 8) + "\n  " + prefix("e.f/", 32) + "  \n", 3},
+		{"duplicates", prefix("a.b/", 4) + "\n" + prefix("a.b/", 4) + "\n", 1},
+		{"upper case", strings.ToUpper(prefix("a.b/", 4)), 1},
+		{"too short", "abcdef", -1},
+		{"too long", prefix("a.b/", 32) + "00", -1},
+		{"odd length", "abcdef012", -1},
+		{"not hex", "nothexatall", -1},
+	}
+
+	for _, tt := range tests {
+		list, err := ParseThreatList(strings.NewReader(tt.list))
+		switch {
+		case tt.want < 0:
+			if err == nil {
+				t.Errorf("%s: ParseThreatList() succeeded, want an error", tt.name)
+			}
+		case err != nil:
+			t.Errorf("%s: ParseThreatList() = %v", tt.name, err)
+		case list.Len() != tt.want:
+			t.Errorf("%s: Len() = %d, want %d", tt.name, list.Len(), tt.want)
+		}
+	}
+
+	_, err := ParseThreatList(strings.NewReader("# ok\n" + prefix("a.b/", 4) + "\nzz\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("ParseThreatList() = %v, want an error on line 3", err)
+	}
+}
+
+func TestThreatListScreen(t *testing.T) {
+	list, err := ParseThreatList(strings.NewReader(strings.Join([]string{
+		prefix("evil.example/", 4),
+		prefix("example.com/malware/", 8),
+		prefix("example.org/login.php?next=1", 32),
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"https://evil.example/", true},
+		{"https://EVIL.example./anything/at/all?x=1", true},
+		{"https://a.b.c.evil.example/", true},
+		{"https://notevil.example/", false},
+		{"https://example.com/malware/", true},
+		{"https://example.com/malware/payload.exe?id=7", true},
+		{"https://cdn.example.com/malware/x", true},
+		{"https://example.com/malware", false},
+		{"https://example.com/", false},
+		{"https://example.org/login.php?next=1", true},
+		{"https://example.org/login.php?next=2", false},
+		{"https://example.org/login.php", false},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := list.Screen(u); (err != nil) != tt.blocked {
+			t.Errorf("Screen(%q) = %v, want blocked %v", tt.url, err, tt.blocked)
+		}
+	}
+}